@@ -0,0 +1,160 @@
+package terratestPlus
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mednax-it/terratest-plus/deployment"
+)
+
+/*
+	LookupAttributePath walks a dotted attribute path, such as `network_rules.0.ip_rules` or `identity.0.principal_id`, through nested attributes.
+
+Numeric segments index into lists (nested blocks are lists in the State, hence the `.0.`). A `*` segment fans out over every element of a list or every value of a map (in key order), so `site_config.*.ftps_state` returns one value per block.
+
+Returns every value found. The error names the path segment that could not be found.
+*/
+func lookupAttributePath(value interface{}, path string) ([]interface{}, error) {
+	current := []interface{}{value}
+	segments := strings.Split(path, ".")
+
+	for i, segment := range segments {
+		next := make([]interface{}, 0, len(current))
+		for _, item := range current {
+			found, err := lookupAttributeSegment(item, segment)
+			if err != nil {
+				return nil, fmt.Errorf("segment %q (%s) of path %s: %v", segment, strings.Join(segments[:i+1], "."), path, err)
+			}
+			next = append(next, found...)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+/*
+LookupAttributeSegment resolves one segment of an attribute path against a map or list, returning multiple values for `*`.
+*/
+func lookupAttributeSegment(item interface{}, segment string) ([]interface{}, error) {
+	switch value := item.(type) {
+	case map[string]interface{}:
+		if segment == "*" {
+			keys := make([]string, 0, len(value))
+			for key := range value {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			found := make([]interface{}, 0, len(keys))
+			for _, key := range keys {
+				found = append(found, value[key])
+			}
+			return found, nil
+		}
+
+		found, ok := value[segment]
+		if !ok {
+			return nil, fmt.Errorf("not found")
+		}
+		return []interface{}{found}, nil
+
+	case []interface{}:
+		if segment == "*" {
+			return value, nil
+		}
+
+		index, err := strconv.Atoi(segment)
+		if err != nil {
+			return nil, fmt.Errorf("is a list and needs a numeric index or *")
+		}
+		if index < 0 || index >= len(value) {
+			return nil, fmt.Errorf("index out of range (length %d)", len(value))
+		}
+		return []interface{}{value[index]}, nil
+
+	case nil:
+		return nil, fmt.Errorf("parent is null")
+	}
+
+	return nil, fmt.Errorf("parent is a %T, not a block, list or map", item)
+}
+
+/*
+	MaskAttributePaths replaces the value at each dotted path with deployment.SensitiveValue, in place. Paths that are not found or are null are left alone.
+
+When the value is a block, list or map, every value inside it is masked instead, so the attribute keeps its type.
+Paths are those of deployment.SensitivePaths, so `*` is not supported.
+*/
+func maskAttributePaths(attributes map[string]interface{}, paths []string) {
+	for _, path := range paths {
+		segments := strings.Split(path, ".")
+		parent := []interface{}{attributes}
+		if len(segments) > 1 {
+			found, err := lookupAttributePath(attributes, strings.Join(segments[:len(segments)-1], "."))
+			if err != nil || len(found) != 1 {
+				continue
+			}
+			parent = found
+		}
+
+		last := segments[len(segments)-1]
+		switch container := parent[0].(type) {
+		case map[string]interface{}:
+			if value, ok := container[last]; ok {
+				container[last] = maskAttributeValue(value)
+			}
+		case []interface{}:
+			if index, err := strconv.Atoi(last); err == nil && index >= 0 && index < len(container) {
+				container[index] = maskAttributeValue(container[index])
+			}
+		}
+	}
+}
+
+/*
+MaskAttributeValue returns deployment.SensitiveValue in place of a value, masking each value inside blocks, lists and maps so their shape is kept. Null values stay null.
+*/
+func maskAttributeValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			masked[key] = maskAttributeValue(item)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(typed))
+		for i, item := range typed {
+			masked[i] = maskAttributeValue(item)
+		}
+		return masked
+	}
+	return deployment.SensitiveValue
+}
+
+/*
+	AttributePathsOverlap is true when the attribute path pattern (which may contain `*` segments) and any of the paths refer to the same attribute, or one is nested in the other.
+
+e.g. `network_rules.*` overlaps `network_rules.0.ip_rules`, and `site_config.0.password` overlaps `site_config`.
+*/
+func attributePathsOverlap(pattern string, paths []string) bool {
+	patternSegments := strings.Split(pattern, ".")
+	for _, path := range paths {
+		pathSegments := strings.Split(path, ".")
+		overlaps := true
+		for i := 0; i < len(patternSegments) && i < len(pathSegments); i++ {
+			if patternSegments[i] != "*" && patternSegments[i] != pathSegments[i] {
+				overlaps = false
+				break
+			}
+		}
+		if overlaps {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package terratestPlus
+
+import (
+	"testing"
+
+	"github.com/mednax-it/terratest-plus/deployment"
+	"github.com/stretchr/testify/assert"
+)
+
+func SetupMockAttributes() map[string]interface{} {
+	return map[string]interface{}{
+		"name": "sdbideletemetemp",
+		"network_rules": []interface{}{
+			map[string]interface{}{
+				"default_action": "Deny",
+				"ip_rules":       []interface{}{"10.0.0.1", "10.0.0.2"},
+			},
+		},
+		"site_config": []interface{}{
+			map[string]interface{}{"ftps_state": "Disabled"},
+			map[string]interface{}{"ftps_state": "FtpsOnly"},
+		},
+		"tags":     map[string]interface{}{"b": "2", "a": "1"},
+		"identity": nil,
+	}
+}
+
+func TestLookupAttributePathTopLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	values, err := lookupAttributePath(SetupMockAttributes(), "name")
+
+	assert.NoError(err)
+	assert.Equal([]interface{}{"sdbideletemetemp"}, values, "Top level attribute was not found")
+}
+
+func TestLookupAttributePathWithListIndex(t *testing.T) {
+	assert := assert.New(t)
+
+	values, err := lookupAttributePath(SetupMockAttributes(), "network_rules.0.ip_rules.1")
+
+	assert.NoError(err)
+	assert.Equal([]interface{}{"10.0.0.2"}, values, "Nested list value was not found")
+}
+
+func TestLookupAttributePathFansOut(t *testing.T) {
+	assert := assert.New(t)
+
+	blocks, err := lookupAttributePath(SetupMockAttributes(), "site_config.*.ftps_state")
+	tags, _ := lookupAttributePath(SetupMockAttributes(), "tags.*")
+
+	assert.NoError(err)
+	assert.Equal([]interface{}{"Disabled", "FtpsOnly"}, blocks, "Fan out over a list did not return every value")
+	assert.Equal([]interface{}{"1", "2"}, tags, "Fan out over a map was not in key order")
+}
+
+func TestLookupAttributePathNamesMissingSegment(t *testing.T) {
+	assert := assert.New(t)
+
+	_, missingKey := lookupAttributePath(SetupMockAttributes(), "network_rules.0.bypass")
+	_, outOfRange := lookupAttributePath(SetupMockAttributes(), "network_rules.3.ip_rules")
+	_, nullParent := lookupAttributePath(SetupMockAttributes(), "identity.0.principal_id")
+
+	assert.ErrorContains(missingKey, `segment "bypass" (network_rules.0.bypass)`, "Missing key error did not name the segment")
+	assert.ErrorContains(outOfRange, `segment "3" (network_rules.3)`, "Out of range error did not name the segment")
+	assert.ErrorContains(nullParent, `segment "0" (identity.0)`, "Null parent error did not name the segment")
+}
+
+func TestGetInstanceAttributeValuesOfResourceWithNestedPath(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	ipRules := testStruct.GetInstanceAttributeValuesOfResource("azurerm_storage_account", "network_rules.*.ip_rules.*")
+	principals := testStruct.GetInstanceAttributeValuesOfResource("azurerm_storage_account", "identity.*.principal_id")
+
+	assert.Equal([]interface{}{"10.0.0.1", "10.0.0.2"}, ipRules, "Nested values were not pulled from the instance")
+	assert.Equal([]interface{}{"11111111-1111-1111-1111-111111111111"}, principals, "Fanned out values were not pulled from the instance")
+}
+
+func TestMaskAttributePathsKeepsTypes(t *testing.T) {
+	assert := assert.New(t)
+	attributes := SetupMockAttributes()
+
+	maskAttributePaths(attributes, []string{"name", "network_rules.0.ip_rules", "tags", "identity"})
+
+	assert.Equal(deployment.SensitiveValue, attributes["name"], "Value was not masked")
+	assert.Equal([]interface{}{deployment.SensitiveValue, deployment.SensitiveValue}, attributes["network_rules"].([]interface{})[0].(map[string]interface{})["ip_rules"], "List was not masked as a list")
+	assert.Equal(map[string]interface{}{"a": deployment.SensitiveValue, "b": deployment.SensitiveValue}, attributes["tags"], "Map was not masked as a map")
+	assert.Equal("Deny", attributes["network_rules"].([]interface{})[0].(map[string]interface{})["default_action"], "Value next to a masked path was masked")
+	assert.Nil(attributes["identity"], "Null value was masked")
+}
+
+func TestMaskAttributePathsWithUnreadableStep(t *testing.T) {
+	assert := assert.New(t)
+	attributes := SetupMockAttributes()
+	sensitiveAttributes := []interface{}{
+		[]interface{}{
+			map[string]interface{}{"type": "get_attr", "value": "network_rules"},
+			map[string]interface{}{"type": "index", "value": map[string]interface{}{"value": true, "type": "bool"}},
+			map[string]interface{}{"type": "get_attr", "value": "ip_rules"},
+		},
+	}
+
+	paths := deployment.SensitivePaths(sensitiveAttributes)
+	maskAttributePaths(attributes, paths)
+
+	assert.Equal([]string{"network_rules"}, paths, "Path did not stop at the unreadable step")
+	networkRules, isList := attributes["network_rules"].([]interface{})
+	assert.True(isList, "Parent of the unreadable step did not stay a list")
+	assert.Equal(map[string]interface{}{
+		"default_action": deployment.SensitiveValue,
+		"ip_rules":       []interface{}{deployment.SensitiveValue, deployment.SensitiveValue},
+	}, networkRules[0], "Values below the unreadable step were not masked")
+	assert.Equal("sdbideletemetemp", attributes["name"], "Attribute outside the path was masked")
+}
+
+func TestAttributePathsOverlap(t *testing.T) {
+	assert := assert.New(t)
+	sensitive := []string{"network_rules.0.ip_rules", "primary_access_key"}
+
+	assert.True(attributePathsOverlap("network_rules.*", sensitive), "Parent of a sensitive path did not overlap")
+	assert.True(attributePathsOverlap("network_rules.*.ip_rules.*", sensitive), "Child of a sensitive path did not overlap")
+	assert.True(attributePathsOverlap("primary_access_key", sensitive), "Sensitive path did not overlap itself")
+	assert.False(attributePathsOverlap("network_rules.0.default_action", sensitive), "Sibling of a sensitive path overlapped")
+}
@@ -0,0 +1,295 @@
+package deployment
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+	ModuleStep is one `module.name[key]` step of a module path.
+
+Key is nil for a module without count or for_each, an int for count and a string for for_each.
+*/
+type ModuleStep struct {
+	Name string
+	Key  interface{}
+}
+
+/*
+	Address is a terraform resource (or resource instance) address, such as `module.a["x"].module.b[0].azurerm_x.y["k"]`.
+
+Key is the instance key (nil, int or string) - an Address without a Key refers to the whole resource.
+*/
+type Address struct {
+	Module []ModuleStep
+	Mode   string
+	Type   string
+	Name   string
+	Key    interface{}
+}
+
+/*
+	ParseAddress parses a full terraform address into an Address.
+
+Both the terraform format (`module.x["a"].azurerm_y.z["k"]`) and the cleaned format used throughout terratestPlus (`module.x[a].azurerm_y.z[k]`) are accepted. Unquoted keys made up of digits are treated as count indexes.
+*/
+func ParseAddress(address string) (Address, error) {
+	parsed := Address{Mode: "managed"}
+	p := &addressParser{input: address}
+
+	steps, err := p.parseModuleSteps()
+	if err != nil {
+		return parsed, err
+	}
+	parsed.Module = steps
+
+	if p.done() {
+		return parsed, fmt.Errorf("address %q has no resource", address)
+	}
+
+	parsed.Type = p.readIdentifier()
+	if parsed.Type == "data" {
+		parsed.Mode = "data"
+		if err := p.expect('.'); err != nil {
+			return parsed, err
+		}
+		parsed.Type = p.readIdentifier()
+	}
+
+	if err := p.expect('.'); err != nil {
+		return parsed, err
+	}
+	parsed.Name = p.readIdentifier()
+	if parsed.Type == "" || parsed.Name == "" {
+		return parsed, fmt.Errorf("address %q has an empty resource type or name", address)
+	}
+
+	if parsed.Key, err = p.parseKey(); err != nil {
+		return parsed, err
+	}
+
+	if !p.done() {
+		return parsed, fmt.Errorf("unexpected %q at position %d of address %q", p.input[p.pos:], p.pos, address)
+	}
+	return parsed, nil
+}
+
+/*
+ParseModulePath parses a module path such as `module.a["x"].module.b[0]` (or its cleaned format) into its steps. An empty path is the root module.
+*/
+func ParseModulePath(path string) ([]ModuleStep, error) {
+	p := &addressParser{input: path}
+
+	steps, err := p.parseModuleSteps()
+	if err != nil {
+		return steps, err
+	}
+
+	if !p.done() {
+		return steps, fmt.Errorf("module path %q contains a resource", path)
+	}
+	return steps, nil
+}
+
+/*
+NewResourceAddress builds the Address of a whole resource from the module, mode, type and name fields recorded in a state file.
+*/
+func NewResourceAddress(module string, mode string, resourceType string, name string) (Address, error) {
+	steps, err := ParseModulePath(module)
+	if mode == "" {
+		mode = "managed"
+	}
+	return Address{Module: steps, Mode: mode, Type: resourceType, Name: name}, err
+}
+
+// String formats the Address the way terraform does, with for_each keys quoted.
+func (a Address) String() string {
+	return a.format(true)
+}
+
+// CleanString formats the Address with for_each keys unquoted, matching the cleaned module addresses of the State.
+func (a Address) CleanString() string {
+	return a.format(false)
+}
+
+// ModulePath formats only the module part of the Address the way terraform does. It is empty for the root module.
+func (a Address) ModulePath() string {
+	return formatModuleSteps(a.Module, true)
+}
+
+// CleanModulePath formats only the module part of the Address with for_each keys unquoted.
+func (a Address) CleanModulePath() string {
+	return formatModuleSteps(a.Module, false)
+}
+
+/*
+	ConfigAddress formats the Address without any module or instance keys, e.g. `module.platforms.azurerm_x.y`.
+
+This is the form terraform records in the `dependencies` of a state instance.
+*/
+func (a Address) ConfigAddress() string {
+	config := Address{Mode: a.Mode, Type: a.Type, Name: a.Name}
+	for _, step := range a.Module {
+		config.Module = append(config.Module, ModuleStep{Name: step.Name})
+	}
+	return config.String()
+}
+
+// ResourceAddress returns the Address of the whole resource, without the instance key.
+func (a Address) ResourceAddress() Address {
+	a.Key = nil
+	return a
+}
+
+func (a Address) format(quote bool) string {
+	parts := make([]string, 0, 4)
+	if module := formatModuleSteps(a.Module, quote); module != "" {
+		parts = append(parts, module)
+	}
+	if a.Mode == "data" {
+		parts = append(parts, "data")
+	}
+	parts = append(parts, a.Type, a.Name+formatKey(a.Key, quote))
+	return strings.Join(parts, ".")
+}
+
+func formatModuleSteps(steps []ModuleStep, quote bool) string {
+	parts := make([]string, 0, len(steps))
+	for _, step := range steps {
+		parts = append(parts, "module."+step.Name+formatKey(step.Key, quote))
+	}
+	return strings.Join(parts, ".")
+}
+
+/*
+	FormatKey formats an instance key in brackets. String keys are quoted only when quote is true.
+
+Numbers decoded from json arrive as float64, so they are formatted as count indexes as well.
+*/
+func formatKey(key interface{}, quote bool) string {
+	switch value := key.(type) {
+	case nil:
+		return ""
+	case string:
+		if quote {
+			return "[" + strconv.Quote(value) + "]"
+		}
+		return "[" + value + "]"
+	case float64:
+		return "[" + strconv.FormatFloat(value, 'f', -1, 64) + "]"
+	default:
+		return fmt.Sprintf("[%v]", value)
+	}
+}
+
+/*
+InstanceKeyString formats an instance key without brackets or quotes, so a count index of 0 is "0" and a for_each key of "a" is "a". A nil key is "".
+*/
+func InstanceKeyString(key interface{}) string {
+	formatted := formatKey(key, false)
+	if formatted == "" {
+		return ""
+	}
+	return formatted[1 : len(formatted)-1]
+}
+
+type addressParser struct {
+	input string
+	pos   int
+}
+
+func (p *addressParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *addressParser) expect(char byte) error {
+	if p.done() || p.input[p.pos] != char {
+		return fmt.Errorf("expected %q at position %d of address %q", char, p.pos, p.input)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *addressParser) readIdentifier() string {
+	start := p.pos
+	for !p.done() && p.input[p.pos] != '.' && p.input[p.pos] != '[' {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+/*
+ParseModuleSteps consumes every leading `module.name[key].` step, leaving the parser at the start of the resource.
+*/
+func (p *addressParser) parseModuleSteps() ([]ModuleStep, error) {
+	steps := make([]ModuleStep, 0)
+	for strings.HasPrefix(p.input[p.pos:], "module.") {
+		p.pos += len("module.")
+
+		step := ModuleStep{Name: p.readIdentifier()}
+		if step.Name == "" {
+			return steps, fmt.Errorf("empty module name at position %d of address %q", p.pos, p.input)
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return steps, err
+		}
+		step.Key = key
+		steps = append(steps, step)
+
+		if p.done() {
+			break
+		}
+		if err := p.expect('.'); err != nil {
+			return steps, err
+		}
+	}
+	return steps, nil
+}
+
+/*
+	ParseKey consumes an optional `[key]`, returning nil when there is no key.
+
+Quoted keys are always strings; unquoted keys made up of digits are ints.
+*/
+func (p *addressParser) parseKey() (interface{}, error) {
+	if p.done() || p.input[p.pos] != '[' {
+		return nil, nil
+	}
+	p.pos++
+
+	if !p.done() && p.input[p.pos] == '"' {
+		start := p.pos
+		p.pos++
+		for !p.done() && p.input[p.pos] != '"' {
+			if p.input[p.pos] == '\\' {
+				p.pos++
+			}
+			p.pos++
+		}
+		if p.done() {
+			return nil, fmt.Errorf("unterminated key in address %q", p.input)
+		}
+		p.pos++
+
+		key, err := strconv.Unquote(p.input[start:p.pos])
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s in address %q: %v", p.input[start:p.pos], p.input, err)
+		}
+		return key, p.expect(']')
+	}
+
+	end := strings.IndexByte(p.input[p.pos:], ']')
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated key in address %q", p.input)
+	}
+	raw := p.input[p.pos : p.pos+end]
+	p.pos += end + 1
+
+	if index, err := strconv.Atoi(raw); err == nil {
+		return index, nil
+	}
+	return raw, nil
+}
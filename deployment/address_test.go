@@ -0,0 +1,110 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAddressOfNestedModules(t *testing.T) {
+	assert := assert.New(t)
+	addressString := `module.a["x"].module.b[0].azurerm_x.y["k"]`
+
+	address, err := ParseAddress(addressString)
+
+	assert.NoError(err)
+	assert.Equal([]ModuleStep{{Name: "a", Key: "x"}, {Name: "b", Key: 0}}, address.Module, "Module path was not parsed")
+	assert.Equal("managed", address.Mode, "Mode did not default to managed")
+	assert.Equal("azurerm_x", address.Type, "Type was not parsed")
+	assert.Equal("y", address.Name, "Name was not parsed")
+	assert.Equal("k", address.Key, "Instance key was not parsed")
+}
+
+func TestParseAddressRoundTrips(t *testing.T) {
+	assert := assert.New(t)
+	addresses := []string{
+		`module.a["x"].module.b[0].azurerm_x.y["k"]`,
+		`azurerm_resource_group.example`,
+		`azurerm_storage_account.exampleCount[0]`,
+		`data.azurerm_client_config.current`,
+		`module.platforms["a.b"].data.azurerm_subscription.this`,
+	}
+
+	for _, addressString := range addresses {
+		address, err := ParseAddress(addressString)
+
+		assert.NoError(err)
+		assert.Equalf(addressString, address.String(), "Address %s did not format back to itself", addressString)
+	}
+}
+
+func TestParseAddressAcceptsCleanedFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	address, err := ParseAddress("module.platforms[alpha].azurerm_storage_account.this[0]")
+
+	assert.NoError(err)
+	assert.Equal("alpha", address.Module[0].Key, "Cleaned module key was not parsed as a string")
+	assert.Equal(0, address.Key, "Cleaned count index was not parsed as an int")
+	assert.Equal(`module.platforms["alpha"].azurerm_storage_account.this[0]`, address.String(), "Cleaned address did not format to the terraform format")
+}
+
+func TestParseAddressDataMode(t *testing.T) {
+	assert := assert.New(t)
+
+	address, err := ParseAddress("data.azurerm_client_config.current")
+
+	assert.NoError(err)
+	assert.Equal("data", address.Mode, "Data mode was not parsed")
+	assert.Equal("azurerm_client_config", address.Type, "Data type was not parsed")
+}
+
+func TestParseAddressRejectsInvalidAddresses(t *testing.T) {
+	assert := assert.New(t)
+	invalid := []string{
+		"",
+		"module.platforms",
+		"azurerm_storage_account",
+		`azurerm_storage_account.this["unterminated]`,
+		"azurerm_storage_account.this[0]extra",
+	}
+
+	for _, addressString := range invalid {
+		_, err := ParseAddress(addressString)
+
+		assert.Errorf(err, "Address %s should not parse", addressString)
+	}
+}
+
+func TestCleanStringUnquotesKeys(t *testing.T) {
+	assert := assert.New(t)
+	address, _ := ParseAddress(`module.a["x"].module.b[0].azurerm_x.y["k"]`)
+
+	assert.Equal("module.a[x].module.b[0].azurerm_x.y[k]", address.CleanString(), "CleanString did not unquote keys")
+	assert.Equal(`module.a["x"].module.b[0]`, address.ModulePath(), "ModulePath was not formatted")
+	assert.Equal("module.a[x].module.b[0].azurerm_x.y", address.ResourceAddress().CleanString(), "ResourceAddress did not drop the instance key")
+}
+
+func TestNewResourceAddressForRootModule(t *testing.T) {
+	assert := assert.New(t)
+
+	address, err := NewResourceAddress("", "", "azurerm_resource_group", "example")
+
+	assert.NoError(err)
+	assert.Empty(address.Module, "Root module resource should have no module steps")
+	assert.Equal("azurerm_resource_group.example", address.String(), "Root module address should have no module prefix")
+}
+
+func TestFormatKeyTreatsJsonNumbersAsIndexes(t *testing.T) {
+	assert := assert.New(t)
+	address := Address{Type: "azurerm_x", Name: "y", Key: float64(2)}
+
+	assert.Equal("azurerm_x.y[2]", address.String(), "Float key was not formatted as an index")
+}
+
+func TestConfigAddressDropsAllKeys(t *testing.T) {
+	assert := assert.New(t)
+	address, _ := ParseAddress(`module.a["x"].module.b[0].azurerm_x.y["k"]`)
+
+	assert.Equal("module.a.module.b.azurerm_x.y", address.ConfigAddress(), "ConfigAddress did not drop the keys")
+}
@@ -15,6 +15,10 @@ type D struct {
 	BackendValues    map[string]interface{}
 	VarFileValues    map[string]interface{}
 	OutputValues     map[string]interface{}
+	Outputs          map[string]*StateOutput
+	Plan             *TerraformPlan
+	RawPlan          map[string]interface{}
+	ProviderLocks    map[string]*ProviderLock
 
 	// Reference variables for display and output primarily
 	RunInit            bool
@@ -23,4 +27,7 @@ type D struct {
 	VarFilePath        string
 	BackendFilePath    string
 	TerraformSourceDir string
+
+	// When true, module addresses in State and RawState are left as terraform wrote them (`module.x["a"]`)
+	KeepRawModuleAddresses bool
 }
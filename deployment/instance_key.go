@@ -0,0 +1,93 @@
+package deployment
+
+import (
+	"encoding/json"
+	"math"
+)
+
+/*
+	InstanceKey is the `index_key` of a resource instance: a count index, a for_each key or nothing at all.
+
+Numbers decoded from json arrive as float64, so they are stored as an int - an InstanceKey built from `0` or `float64(0)` is the same count index.
+*/
+type InstanceKey struct {
+	value interface{}
+}
+
+/*
+NewInstanceKey builds an InstanceKey from a string (for_each), any whole number (count) or nil (neither).
+*/
+func NewInstanceKey(value interface{}) InstanceKey {
+	switch key := value.(type) {
+	case InstanceKey:
+		return key
+	case float64:
+		if key == math.Trunc(key) {
+			return InstanceKey{value: int(key)}
+		}
+	case float32:
+		if float64(key) == math.Trunc(float64(key)) {
+			return InstanceKey{value: int(key)}
+		}
+	case int8:
+		return InstanceKey{value: int(key)}
+	case int16:
+		return InstanceKey{value: int(key)}
+	case int32:
+		return InstanceKey{value: int(key)}
+	case int64:
+		return InstanceKey{value: int(key)}
+	}
+	return InstanceKey{value: value}
+}
+
+// Value returns the key as a string, an int or nil.
+func (k InstanceKey) Value() interface{} {
+	return k.value
+}
+
+// String returns the key without brackets or quotes, so a count index of 0 is "0". It is empty when there is no key.
+func (k InstanceKey) String() string {
+	return InstanceKeyString(k.value)
+}
+
+// Int returns the count index, and false if the key is not a count index.
+func (k InstanceKey) Int() (int, bool) {
+	index, ok := k.value.(int)
+	return index, ok
+}
+
+// IsCount is true when the instance was created by count.
+func (k InstanceKey) IsCount() bool {
+	_, ok := k.value.(int)
+	return ok
+}
+
+// IsForEach is true when the instance was created by for_each.
+func (k InstanceKey) IsForEach() bool {
+	_, ok := k.value.(string)
+	return ok
+}
+
+// IsNone is true when the resource has neither count nor for_each.
+func (k InstanceKey) IsNone() bool {
+	return k.value == nil
+}
+
+// Equal is true when both keys are the same kind of key with the same value.
+func (k InstanceKey) Equal(other InstanceKey) bool {
+	return k.value == other.value
+}
+
+func (k InstanceKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.value)
+}
+
+func (k *InstanceKey) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*k = NewInstanceKey(value)
+	return nil
+}
@@ -0,0 +1,57 @@
+package deployment
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceKeyNormalisesJsonNumbers(t *testing.T) {
+	assert := assert.New(t)
+
+	fromJson := NewInstanceKey(float64(2))
+	index, isCount := fromJson.Int()
+
+	assert.True(isCount, "Whole float64 was not treated as a count index")
+	assert.Equal(2, index, "Count index was not kept")
+	assert.True(fromJson.Equal(NewInstanceKey(2)), "float64 and int keys of the same value are not equal")
+	assert.Equal("2", fromJson.String(), "Count index was not formatted")
+}
+
+func TestInstanceKeyKinds(t *testing.T) {
+	assert := assert.New(t)
+
+	forEach := NewInstanceKey("alpha")
+	none := NewInstanceKey(nil)
+	_, isCount := forEach.Int()
+
+	assert.True(forEach.IsForEach(), "String key was not a for_each key")
+	assert.False(isCount, "for_each key returned a count index")
+	assert.Equal("alpha", forEach.String(), "for_each key was not returned as is")
+	assert.True(none.IsNone(), "nil key was not treated as no key")
+	assert.Equal("", none.String(), "Missing key was not formatted as empty")
+	assert.False(NewInstanceKey("0").Equal(NewInstanceKey(0)), "for_each key equalled a count index")
+}
+
+func TestInstanceKeyDecodesFromState(t *testing.T) {
+	assert := assert.New(t)
+	var instances []StateResourceInstance
+
+	err := json.Unmarshal([]byte(`[{"index_key": 1}, {"index_key": "beta"}, {"index_key": null}, {}]`), &instances)
+
+	assert.NoError(err)
+	assert.True(instances[0].IndexKey.IsCount(), "Numeric index_key was not decoded as a count index")
+	assert.True(instances[1].IndexKey.IsForEach(), "String index_key was not decoded as a for_each key")
+	assert.True(instances[2].IndexKey.IsNone(), "null index_key was not decoded as no key")
+	assert.True(instances[3].IndexKey.IsNone(), "Missing index_key was not decoded as no key")
+}
+
+func TestInstanceKeyEncodesAsItsValue(t *testing.T) {
+	assert := assert.New(t)
+
+	encoded, err := json.Marshal(StateResourceInstance{IndexKey: NewInstanceKey("alpha")})
+
+	assert.NoError(err)
+	assert.Contains(string(encoded), `"index_key":"alpha"`, "Key was not encoded as its value")
+}
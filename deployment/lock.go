@@ -0,0 +1,13 @@
+package deployment
+
+/*
+	ProviderLock is a `provider` block of the dependency lock file (`.terraform.lock.hcl`).
+
+Source is the full provider address, such as `registry.terraform.io/hashicorp/azurerm`. Constraints is empty when the configuration has no version constraint for the provider.
+*/
+type ProviderLock struct {
+	Source      string   `hcl:"source,label"`
+	Version     string   `hcl:"version"`
+	Constraints string   `hcl:"constraints,optional"`
+	Hashes      []string `hcl:"hashes,optional"`
+}
@@ -0,0 +1,33 @@
+package deployment
+
+type TerraformPlan struct {
+	FormatVersion    string                 `json:"format_version"`
+	TerraformVersion string                 `json:"terraform_version"`
+	ResourceChanges  []*PlanResourceChange  `json:"resource_changes,omitempty"`
+	ResourceDrift    []*PlanResourceChange  `json:"resource_drift,omitempty"`
+	OutputChanges    map[string]*PlanChange `json:"output_changes,omitempty"`
+	Errored          bool                   `json:"errored"`
+}
+
+type PlanResourceChange struct {
+	Address       string      `json:"address"`
+	ModuleAddress string      `json:"module_address,omitempty"`
+	Mode          string      `json:"mode"`
+	Type          string      `json:"type"`
+	Name          string      `json:"name"`
+	Index         interface{} `json:"index,omitempty"`
+	Deposed       string      `json:"deposed,omitempty"`
+	ProviderName  string      `json:"provider_name,omitempty"`
+	ActionReason  string      `json:"action_reason,omitempty"`
+	Change        *PlanChange `json:"change"`
+}
+
+type PlanChange struct {
+	Actions         []string      `json:"actions"`
+	Before          interface{}   `json:"before"`
+	After           interface{}   `json:"after"`
+	AfterUnknown    interface{}   `json:"after_unknown,omitempty"`
+	BeforeSensitive interface{}   `json:"before_sensitive,omitempty"`
+	AfterSensitive  interface{}   `json:"after_sensitive,omitempty"`
+	ReplacePaths    []interface{} `json:"replace_paths,omitempty"`
+}
@@ -1,7 +1,61 @@
 package deployment
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SensitiveValue is printed and stored in place of any sensitive value.
+const SensitiveValue = "(sensitive value)"
+
 type TerraformState struct {
-	Resources []*StateResource `json:"resources"`
+	Version          int                     `json:"version"`
+	TerraformVersion string                  `json:"terraform_version"`
+	Serial           int                     `json:"serial"`
+	Lineage          string                  `json:"lineage"`
+	Outputs          map[string]*StateOutput `json:"outputs,omitempty"`
+	Resources        []*StateResource        `json:"resources"`
+	CheckResults     []*StateCheckResult     `json:"check_results,omitempty"`
+}
+
+type StateOutput struct {
+	Value     interface{} `json:"value"`
+	Type      interface{} `json:"type,omitempty"`
+	Sensitive bool        `json:"sensitive,omitempty"`
+}
+
+/*
+	String formats the output value, masking it when the output is sensitive.
+
+This is used by the fmt verbs, so an output can be passed to log and assertion messages without leaking sensitive values.
+*/
+func (o *StateOutput) String() string {
+	if o.Sensitive {
+		return SensitiveValue
+	}
+	return fmt.Sprintf("%v", o.Value)
+}
+
+// GoString masks sensitive values for the %#v verb as well.
+func (o *StateOutput) GoString() string {
+	if o.Sensitive {
+		return SensitiveValue
+	}
+	return fmt.Sprintf("%#v", o.Value)
+}
+
+type StateCheckResult struct {
+	ObjectKind string                    `json:"object_kind"`
+	ConfigAddr string                    `json:"config_addr"`
+	Status     string                    `json:"status"`
+	Objects    []*StateCheckResultObject `json:"objects,omitempty"`
+}
+
+type StateCheckResultObject struct {
+	ObjectAddr      string   `json:"object_addr"`
+	Status          string   `json:"status"`
+	FailureMessages []string `json:"failure_messages,omitempty"`
 }
 
 type StateResource struct {
@@ -14,8 +68,104 @@ type StateResource struct {
 }
 
 type StateResourceInstance struct {
-	Attributes *StateResourceAttributes `json:"attributes"`
-	IndexKey   interface{}              `json:"index_key"`
+	Attributes          *StateResourceAttributes `json:"attributes"`
+	IndexKey            InstanceKey              `json:"index_key"`
+	Status              string                   `json:"status,omitempty"`
+	Deposed             string                   `json:"deposed,omitempty"`
+	SchemaVersion       int                      `json:"schema_version"`
+	Dependencies        []string                 `json:"dependencies,omitempty"`
+	SensitiveAttributes []interface{}            `json:"sensitive_attributes,omitempty"`
+	CreateBeforeDestroy bool                     `json:"create_before_destroy,omitempty"`
+
+	// Every attribute of the instance as terraform recorded it, set when the State is loaded from json
+	RawAttributes map[string]interface{} `json:"-"`
+}
+
+/*
+	AttributeMap returns every attribute of the instance.
+
+When the State was loaded from json this is RawAttributes, otherwise it is built from the typed Attributes.
+*/
+func (i *StateResourceInstance) AttributeMap() map[string]interface{} {
+	if i.RawAttributes != nil {
+		return i.RawAttributes
+	}
+
+	attributes := make(map[string]interface{})
+	if i.Attributes == nil {
+		return attributes
+	}
+	if i.Attributes.Id != nil {
+		attributes["id"] = i.Attributes.Id
+	}
+	if i.Attributes.Location != nil {
+		attributes["location"] = i.Attributes.Location
+	}
+	if i.Attributes.Name != nil {
+		attributes["name"] = i.Attributes.Name
+	}
+	if i.Attributes.Tags != nil {
+		attributes["tags"] = i.Attributes.Tags
+	}
+	return attributes
+}
+
+// SensitivePaths returns the `sensitive_attributes` of the instance as dotted attribute paths (see SensitivePaths).
+func (i *StateResourceInstance) SensitivePaths() []string {
+	return SensitivePaths(i.SensitiveAttributes)
+}
+
+/*
+	SensitivePaths turns the raw `sensitive_attributes` of an instance into dotted attribute paths, such as `primary_access_key` or `site_config.0.password`.
+
+Terraform records each path as a list of steps (`{"type": "get_attr", "value": "site_config"}`, `{"type": "index", "value": {"value": 0, "type": "number"}}`).
+A path stops at the first step that cannot be read, so it covers the whole parent of that step rather than missing a sensitive value.
+*/
+func SensitivePaths(sensitiveAttributes []interface{}) []string {
+	paths := make([]string, 0, len(sensitiveAttributes))
+	for _, sensitive := range sensitiveAttributes {
+		steps, _ := sensitive.([]interface{})
+		segments := make([]string, 0, len(steps))
+		for _, rawStep := range steps {
+			step, _ := rawStep.(map[string]interface{})
+			segment, ok := sensitivePathSegment(step)
+			if !ok {
+				break
+			}
+			segments = append(segments, segment)
+		}
+		if len(segments) > 0 {
+			paths = append(paths, strings.Join(segments, "."))
+		}
+	}
+	return paths
+}
+
+func sensitivePathSegment(step map[string]interface{}) (string, bool) {
+	switch step["type"] {
+	case "get_attr":
+		name, ok := step["value"].(string)
+		return name, ok
+	case "index":
+		index, _ := step["value"].(map[string]interface{})
+		switch key := index["value"].(type) {
+		case string:
+			return key, true
+		case float64:
+			return strconv.FormatFloat(key, 'f', -1, 64), true
+		}
+	}
+	return "", false
+}
+
+// IsTainted is true when terraform has marked the instance to be replaced on the next apply.
+func (i *StateResourceInstance) IsTainted() bool {
+	return i.Status == "tainted"
+}
+
+// IsDeposed is true when the instance is a deposed object left behind by a create_before_destroy replacement.
+func (i *StateResourceInstance) IsDeposed() bool {
+	return i.Deposed != ""
 }
 
 type StateResourceAttributes struct {
@@ -0,0 +1,55 @@
+package terratestPlus
+
+import (
+	"strings"
+
+	"github.com/mednax-it/terratest-plus/deployment"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	AssertChecksPass fails the test if any `check` block, precondition or postcondition recorded in the State `check_results` has failed or errored, listing each failing object address with its failure messages.
+
+Checks with an `unknown` status could not be evaluated (e.g. they depend on values only known after apply) and do not fail the test.
+
+This is run automatically after apply as part of DeployInfrastructure, unless the Test Structure Stage variable SKIP_terraform_checks is set.
+*/
+func (d *Deployment) AssertChecksPass() bool {
+	failures := failedChecks(d.State.CheckResults)
+	return assert.Emptyf(d.T, failures, "Terraform checks failed:\n%s", strings.Join(failures, "\n"))
+}
+
+/*
+	FailedChecks describes every failed or errored check object as "address (status): messages".
+
+When terraform recorded a failed status for a check without any objects, the config address is described instead.
+*/
+func failedChecks(results []*deployment.StateCheckResult) []string {
+	failures := make([]string, 0)
+	for _, result := range results {
+		described := false
+		for _, object := range result.Objects {
+			if checkFailed(object.Status) {
+				failures = append(failures, describeCheck(object.ObjectAddr, object.Status, object.FailureMessages))
+				described = true
+			}
+		}
+
+		if checkFailed(result.Status) && !described {
+			failures = append(failures, describeCheck(result.ConfigAddr, result.Status, nil))
+		}
+	}
+	return failures
+}
+
+func checkFailed(status string) bool {
+	return status == "fail" || status == "error"
+}
+
+func describeCheck(address string, status string, messages []string) string {
+	description := address + " (" + status + ")"
+	if len(messages) > 0 {
+		description += ": " + strings.Join(messages, "; ")
+	}
+	return description
+}
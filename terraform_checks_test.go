@@ -0,0 +1,63 @@
+package terratestPlus
+
+import (
+	"testing"
+
+	"github.com/mednax-it/terratest-plus/deployment"
+	"github.com/stretchr/testify/assert"
+)
+
+func SetupMockFailedChecksState(t *testing.T) *Deployment {
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromFile("testdata/failed_checks.tfstate")
+	return testStruct
+}
+
+func TestAssertChecksPassPassesOnPassingChecks(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	assert.True(testStruct.AssertChecksPass(), "Passing checks failed the test")
+}
+
+func TestFailedChecksDescribesFailingObjects(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockFailedChecksState(t)
+
+	failures := failedChecks(testStruct.State.CheckResults)
+
+	assert.Equal([]string{
+		"check.storage_health (fail): Storage account is not reachable; TLS 1.0 is enabled",
+		`module.platforms["beta"].azurerm_storage_account.this (error): Invalid postcondition`,
+		"check.dns (error)",
+	}, failures, "Failing checks were not described")
+}
+
+func TestFailedChecksIgnoresUnknownChecks(t *testing.T) {
+	assert := assert.New(t)
+	results := []*deployment.StateCheckResult{{ConfigAddr: "check.endpoint", Status: "unknown"}}
+
+	assert.Empty(failedChecks(results), "Unknown check was treated as a failure")
+}
+
+func TestFailedChecksFailTheDeployment(t *testing.T) {
+	assert := assert.New(t)
+	mockT := new(testing.T)
+	testStruct := SetupMockFailedChecksState(mockT)
+
+	testStruct.checkDeployment()
+
+	assert.True(mockT.Failed(), "Failed checks did not fail the test")
+}
+
+func TestSkipTerraformChecksSkipsFailedChecks(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("SKIP_terraform_checks", "true")
+	mockT := new(testing.T)
+	testStruct := SetupMockFailedChecksState(mockT)
+
+	testStruct.checkDeployment()
+
+	assert.False(mockT.Failed(), "SKIP_terraform_checks did not skip the checks")
+}
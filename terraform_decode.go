@@ -0,0 +1,61 @@
+package terratestPlus
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	DecodeInstances decodes the attributes of every managed instance matching the selector into a caller supplied struct, sorted by instance address.
+
+The selector is either a resource type (e.g. `azurerm_storage_account`) or, if it contains a `.`, an address pattern as in FindByAddress (e.g. `module.platforms[*].azurerm_storage_account.*`).
+
+Decoding goes through encoding/json, so `json` tags, nested blocks (as slices of structs) and numbers as int or float fields all work as expected:
+
+	type StorageAccount struct {
+		Name         string            `json:"name"`
+		AccountTier  string            `json:"account_tier"`
+		Tags         map[string]string `json:"tags"`
+		NetworkRules []struct {
+			IpRules []string `json:"ip_rules"`
+		} `json:"network_rules"`
+	}
+
+	accounts := terratestPlus.DecodeInstances[StorageAccount](d, "azurerm_storage_account")
+
+An instance that cannot be decoded fails the test (naming the address) and is left out of the result.
+*/
+func DecodeInstances[T any](d *Deployment, selector string) []T {
+	query := d.Query().Mode("managed")
+	if strings.Contains(selector, ".") {
+		query = query.Address(selector)
+	} else {
+		query = query.Type(selector)
+	}
+
+	return DecodeQueryInstances[T](query)
+}
+
+/*
+DecodeQueryInstances decodes the attributes of every instance found by a ResourceQuery into a caller supplied struct, sorted by instance address.
+*/
+func DecodeQueryInstances[T any](query *ResourceQuery) []T {
+	decoded := make([]T, 0)
+	for _, instance := range query.Instances() {
+		var value T
+
+		raw, err := json.Marshal(instance.Attributes())
+		if err == nil {
+			err = json.Unmarshal(raw, &value)
+		}
+
+		if err != nil {
+			assert.Failf(query.d.T, "Unable to decode instance", "Unable to decode %s into %T: %v", instance.Address.CleanString(), value, err)
+			continue
+		}
+		decoded = append(decoded, value)
+	}
+	return decoded
+}
@@ -0,0 +1,58 @@
+package terratestPlus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockStorageAccount struct {
+	Name         string            `json:"name"`
+	AccountTier  string            `json:"account_tier"`
+	Tags         map[string]string `json:"tags"`
+	NetworkRules []struct {
+		DefaultAction string   `json:"default_action"`
+		IpRules       []string `json:"ip_rules"`
+	} `json:"network_rules"`
+}
+
+type MockInstanceWithNumbers struct {
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+func TestDecodeInstancesByType(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+	expectedLength := 3
+
+	accounts := DecodeInstances[MockStorageAccount](testStruct, "azurerm_storage_account")
+
+	assert.Equalf(expectedLength, len(accounts), "Incorrect number [%d] of instances decoded (should be %d)", len(accounts), expectedLength)
+	assert.Equal("sdbideletemetemp", accounts[0].Name, "Instances were not sorted by address")
+	assert.Equal("Testing", accounts[0].Tags["environment"], "Map attribute was not decoded")
+	assert.Equal([]string{"10.0.0.1", "10.0.0.2"}, accounts[0].NetworkRules[0].IpRules, "Nested block was not decoded")
+}
+
+func TestDecodeInstancesByAddressPattern(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	accounts := DecodeInstances[MockStorageAccount](testStruct, "module.platforms[*].azurerm_storage_account.this")
+
+	assert.Equal(2, len(accounts), "Address pattern did not select the module instances")
+	assert.Equal("alphasa0", accounts[0].Name, "Instances were not sorted by address")
+	assert.Equal("Premium", accounts[1].AccountTier, "String attribute was not decoded")
+}
+
+func TestDecodeQueryInstancesDecodesNumbers(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromBytes([]byte(`{"resources": [{"mode": "managed", "type": "mock", "name": "numbers", "instances": [{"attributes": {"count": 3, "percent": 0.5}}]}]}`))
+
+	decoded := DecodeQueryInstances[MockInstanceWithNumbers](testStruct.Query().Type("mock"))
+
+	assert.Equal(3, decoded[0].Count, "Number was not decoded as an int")
+	assert.Equal(0.5, decoded[0].Percent, "Number was not decoded as a float")
+}
@@ -0,0 +1,89 @@
+package terratestPlus
+
+import (
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/mednax-it/terratest-plus/deployment"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ResourceDrift is a resource that was changed outside of terraform since the last apply.
+
+Action is `update` when the resource was modified, or `delete` when it no longer exists. For updates, Attributes are the top level attributes that differ from the State.
+*/
+type ResourceDrift struct {
+	Address    string
+	Action     string
+	Attributes []string
+}
+
+func (r ResourceDrift) String() string {
+	if len(r.Attributes) == 0 {
+		return r.Address + " (" + r.Action + ")"
+	}
+	return r.Address + " (" + r.Action + "): " + strings.Join(r.Attributes, ", ")
+}
+
+/*
+DetectDrift runs a refresh-only plan (`terraform plan -refresh-only`) against the deployment and returns every resource that drifted from the State.
+
+This should be run after Apply. Nothing is written to the State, and the Plan var is left untouched.
+
+The plan is saved to a file and read back with `terraform show -json` (the same as GetPlan) rather than using `plan -json`.
+`plan -json` only streams log messages as the plan runs, while the resource_drift section needed here is only in the json of a saved plan.
+*/
+func (d *Deployment) DetectDrift() []ResourceDrift {
+	options := d.planOptions()
+
+	_, err := terraform.RunTerraformCommandE(d.T, options, terraform.FormatArgs(options, "plan", "-input=false", "-refresh-only")...)
+	if err != nil {
+		logger.Logf(d.T, "Error running refresh-only Plan: %v", err)
+		assert.FailNow(d.T, "Terraform refresh-only Plan failed - drift could not be detected.")
+	}
+
+	showPlan, err := terraform.ShowE(d.T, options)
+	if err != nil {
+		logger.Logf(d.T, "Error showing Plan file: %v", err)
+		assert.FailNow(d.T, "Plan File was not able to be converted to json - drift could not be detected.")
+	}
+
+	plan, _ := d.parsePlan([]byte(showPlan))
+	return driftFromPlan(plan)
+}
+
+/*
+AssertNoDrift fails the test if DetectDrift finds any resource that was changed outside of terraform, listing each drifted resource and attribute.
+*/
+func (d *Deployment) AssertNoDrift() bool {
+	drift := d.DetectDrift()
+
+	descriptions := make([]string, 0, len(drift))
+	for _, resource := range drift {
+		descriptions = append(descriptions, resource.String())
+	}
+
+	return assert.Emptyf(d.T, drift, "Resources drifted from the State:\n\t%s", strings.Join(descriptions, "\n\t"))
+}
+
+/*
+DriftFromPlan turns the resource_drift section of a plan into ResourceDrift, skipping anything that did not actually change.
+*/
+func driftFromPlan(plan *deployment.TerraformPlan) []ResourceDrift {
+	drift := make([]ResourceDrift, 0)
+	for _, change := range plan.ResourceDrift {
+		action := planAction(change)
+		if action == "no-op" || action == "read" {
+			continue
+		}
+
+		resource := ResourceDrift{Address: change.Address, Action: action}
+		if action == "update" {
+			resource.Attributes = changedAttributes(change.Change)
+		}
+		drift = append(drift, resource)
+	}
+	return drift
+}
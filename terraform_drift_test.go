@@ -0,0 +1,49 @@
+package terratestPlus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriftFromPlanFindsDriftedResources(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+	testStruct.LoadPlanFromFile("testdata/drift.json")
+	expectedLength := 2
+
+	drift := driftFromPlan(testStruct.Plan)
+
+	assert.Equalf(expectedLength, len(drift), "Incorrect number [%d] of drifted resources (should be %d)", len(drift), expectedLength)
+}
+
+func TestDriftFromPlanListsDriftedAttributes(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+	testStruct.LoadPlanFromFile("testdata/drift.json")
+
+	drift := driftFromPlan(testStruct.Plan)
+
+	assert.Equal("azurerm_storage_account.example", drift[0].Address, "Drifted address was not set")
+	assert.Equal("update", drift[0].Action, "Drifted action was not set")
+	assert.Equal([]string{"min_tls_version", "tags"}, drift[0].Attributes, "Drifted attributes were not found")
+}
+
+func TestResourceDriftStringIncludesAction(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+	testStruct.LoadPlanFromFile("testdata/drift.json")
+
+	drift := driftFromPlan(testStruct.Plan)
+
+	assert.Equal(`module.platforms["beta"].azurerm_storage_account.this[0] (delete)`, drift[1].String(), "Drift description was not readable")
+}
+
+func TestDriftFromPlanWithoutDriftIsEmpty(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+
+	drift := driftFromPlan(testStruct.Plan)
+
+	assert.Empty(drift, "Drift was found in a plan without resource_drift")
+}
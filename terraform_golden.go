@@ -0,0 +1,215 @@
+package terratestPlus
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/mednax-it/terratest-plus/deployment"
+	"github.com/stretchr/testify/assert"
+)
+
+// The placeholder written into golden files in place of the workspace name
+const goldenWorkspacePlaceholder = "${workspace}"
+
+/*
+	GoldenIgnoreRule is an attribute left out of a golden file comparison, such as an id, timestamp or etag that changes on every deployment.
+
+Attribute is a dotted attribute path as in GetInstanceAttributeValuesOfResource, where `*` matches within a single segment (`*_time`, `network_rules.*.id`). Ignoring an attribute ignores everything nested in it.
+
+Address limits the rule to resources matching the pattern, using the same wildcards as FindByAddress. Empty applies the rule to every resource.
+*/
+type GoldenIgnoreRule struct {
+	Address   string
+	Attribute string
+}
+
+/*
+IgnoreAttribute is a GoldenIgnoreRule for an attribute path on every resource, e.g. `IgnoreAttribute("id")` or `IgnoreAttribute("tags.created_on")`.
+*/
+func IgnoreAttribute(path string) GoldenIgnoreRule {
+	return GoldenIgnoreRule{Attribute: path}
+}
+
+/*
+IgnoreResourceAttribute is a GoldenIgnoreRule for an attribute path on the resources matching an address pattern, e.g. `IgnoreResourceAttribute("azurerm_storage_account.*", "primary_*")`.
+*/
+func IgnoreResourceAttribute(address string, path string) GoldenIgnoreRule {
+	return GoldenIgnoreRule{Address: address, Attribute: path}
+}
+
+type goldenInstance struct {
+	IndexKey   interface{}            `json:"index_key,omitempty"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+/*
+	AssertStateMatchesGolden compares the State against a committed golden json file, failing the test with every added, removed or changed instance and attribute.
+
+The golden file is keyed by resource address, using the same cleaned keys as FindAllResourceType. Attributes matching an ignore rule are left out of both sides of the comparison, and of the golden file when it is written. The workspace name is replaced by `${workspace}`, so names derived from the workspace match between runs.
+
+Values of the `sensitive_attributes` of each instance are never written to the golden file - they are replaced by `(sensitive value)` and compared as that placeholder, so a changed secret does not fail the comparison.
+
+Set the env variable UPDATE_GOLDEN=true to (re)write the golden file from the current State instead of comparing.
+*/
+func (d *Deployment) AssertStateMatchesGolden(path string, ignoreRules ...GoldenIgnoreRule) bool {
+	current := d.goldenState(ignoreRules)
+
+	if val := os.Getenv("UPDATE_GOLDEN"); val == "true" {
+		d.writeGolden(path, current)
+		return true
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return assert.Failf(d.T, "Golden file not found", "Could not read golden file %s (set UPDATE_GOLDEN=true to create it): %v", path, err)
+	}
+
+	golden := make(map[string][]*goldenInstance)
+	if err := json.Unmarshal(content, &golden); err != nil {
+		return assert.Failf(d.T, "Invalid golden file", "Could not parse golden file %s: %v", path, err)
+	}
+	applyGoldenIgnoreRules(golden, ignoreRules)
+
+	differences := diffSnapshots(d.goldenSnapshot(golden), d.goldenSnapshot(current)).Descriptions()
+	return assert.Emptyf(d.T, differences, "State does not match golden file %s (set UPDATE_GOLDEN=true to regenerate it):\n%s", path, strings.Join(differences, "\n"))
+}
+
+/*
+GoldenState builds the golden file content of the current State, without deposed objects or ignored attributes, and with the values of sensitive attributes masked.
+*/
+func (d *Deployment) goldenState(ignoreRules []GoldenIgnoreRule) map[string][]*goldenInstance {
+	golden := make(map[string][]*goldenInstance)
+	for _, instance := range d.Query().Instances() {
+		address := instance.Address.ResourceAddress().CleanString()
+		attributes := d.copyAttributes(instance.Attributes())
+		maskAttributePaths(attributes, instance.Instance.SensitivePaths())
+		d.replaceWorkspaceName(attributes)
+		golden[address] = append(golden[address], &goldenInstance{IndexKey: instance.Instance.IndexKey.Value(), Attributes: attributes})
+	}
+
+	applyGoldenIgnoreRules(golden, ignoreRules)
+	return golden
+}
+
+func (d *Deployment) writeGolden(path string, golden map[string][]*goldenInstance) {
+	content, err := json.MarshalIndent(golden, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(path, append(content, '\n'), 0644)
+	}
+
+	if err != nil {
+		assert.Failf(d.T, "Could not write golden file", "Could not write golden file %s: %v", path, err)
+		return
+	}
+	logger.Logf(d.T, "Updated golden file %s", path)
+}
+
+/*
+GoldenSnapshot turns golden file content into a StateSnapshot, so it can be compared like any other.
+*/
+func (d *Deployment) goldenSnapshot(golden map[string][]*goldenInstance) *StateSnapshot {
+	snapshot := &StateSnapshot{Instances: make(map[string]map[string]interface{})}
+	for resourceAddress, instances := range golden {
+		address, err := deployment.ParseAddress(resourceAddress)
+		if err != nil {
+			assert.Failf(d.T, "Invalid golden file", "Could not parse address %s in golden file: %v", resourceAddress, err)
+			continue
+		}
+
+		for _, instance := range instances {
+			address.Key = deployment.NewInstanceKey(instance.IndexKey).Value()
+			snapshot.Instances[address.CleanString()] = instance.Attributes
+		}
+	}
+	return snapshot
+}
+
+/*
+ReplaceWorkspaceName replaces the workspace name in every string attribute with the placeholder. The default workspace is left alone, as `default` is too common to replace.
+*/
+func (d *Deployment) replaceWorkspaceName(attributes map[string]interface{}) {
+	if d.WorkspaceName == "" || d.WorkspaceName == "default" {
+		return
+	}
+
+	var replace func(value interface{}) interface{}
+	replace = func(value interface{}) interface{} {
+		switch typed := value.(type) {
+		case string:
+			return strings.ReplaceAll(typed, d.WorkspaceName, goldenWorkspacePlaceholder)
+		case map[string]interface{}:
+			for key, nested := range typed {
+				typed[key] = replace(nested)
+			}
+		case []interface{}:
+			for i, nested := range typed {
+				typed[i] = replace(nested)
+			}
+		}
+		return value
+	}
+	replace(attributes)
+}
+
+func applyGoldenIgnoreRules(golden map[string][]*goldenInstance, ignoreRules []GoldenIgnoreRule) {
+	for _, rule := range ignoreRules {
+		addressMatcher := compileAddressPattern(rule.Address)
+		attributeMatcher := compileAttributePattern(rule.Attribute)
+
+		for address, instances := range golden {
+			if rule.Address != "" && !addressMatcher.MatchString(address) {
+				continue
+			}
+			for _, instance := range instances {
+				removeMatchingAttributes("", instance.Attributes, attributeMatcher)
+			}
+		}
+	}
+}
+
+/*
+	CompileAttributePattern turns a dotted attribute path pattern into a regex matching the path and everything nested in it.
+
+`*` matches within a single segment, never across a `.`.
+*/
+func compileAttributePattern(pattern string) *regexp.Regexp {
+	segments := strings.Split(pattern, ".")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(regexp.QuoteMeta(segment), `\*`, `[^.]*`)
+	}
+	return regexp.MustCompile(`^` + strings.Join(segments, `\.`) + `(\..*)?$`)
+}
+
+/*
+RemoveMatchingAttributes deletes every map entry below prefix whose path matches. List elements matching are set to nil, so the indexes of the other elements are kept.
+*/
+func removeMatchingAttributes(prefix string, value interface{}, matcher *regexp.Regexp) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range typed {
+			path := joinAttributePath(prefix, key)
+			if matcher.MatchString(path) {
+				delete(typed, key)
+			} else {
+				removeMatchingAttributes(path, nested, matcher)
+			}
+		}
+	case []interface{}:
+		for i, nested := range typed {
+			path := joinAttributePath(prefix, strconv.Itoa(i))
+			if matcher.MatchString(path) {
+				typed[i] = nil
+			} else {
+				removeMatchingAttributes(path, nested, matcher)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package terratestPlus
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertStateMatchesGoldenWritesAndMatchesGoldenFile(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+	path := filepath.Join(t.TempDir(), "golden", "state.json")
+
+	t.Setenv("UPDATE_GOLDEN", "true")
+	testStruct.AssertStateMatchesGolden(path, IgnoreAttribute("id"))
+	t.Setenv("UPDATE_GOLDEN", "")
+
+	content, err := os.ReadFile(path)
+	assert.NoError(err, "Golden file was not written")
+	assert.Contains(string(content), `"module.platforms[alpha].azurerm_storage_account.this"`, "Golden file was not keyed by resource address")
+	assert.NotContains(string(content), `"id":`, "Ignored attribute was written to the golden file")
+	assert.True(testStruct.AssertStateMatchesGolden(path, IgnoreAttribute("id")), "State did not match its own golden file")
+}
+
+func TestGoldenStateAppliesIgnoreRules(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	golden := testStruct.goldenState([]GoldenIgnoreRule{
+		IgnoreAttribute("tags.*"),
+		IgnoreResourceAttribute("module.platforms[*].azurerm_storage_account.*", "account_*"),
+	})
+
+	alpha := golden["module.platforms[alpha].azurerm_storage_account.this"][0].Attributes
+	root := golden["azurerm_storage_account.example"][0].Attributes
+	assert.Empty(alpha["tags"], "Wildcard attribute rule did not remove nested attributes")
+	assert.NotContains(alpha, "account_tier", "Resource rule did not apply to a matching resource")
+	assert.Contains(root, "account_tier", "Resource rule applied to a resource that does not match")
+}
+
+func TestGoldenStateReplacesWorkspaceName(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.WorkspaceName = "abc123"
+	testStruct.LoadStateFromBytes([]byte(`{"resources": [{"mode": "managed", "type": "azurerm_resource_group", "name": "example", "instances": [{"attributes": {"name": "rg-abc123", "tags": {"workspace": "abc123"}}}]}]}`))
+
+	attributes := testStruct.goldenState(nil)["azurerm_resource_group.example"][0].Attributes
+
+	assert.Equal("rg-${workspace}", attributes["name"], "Workspace name was not replaced")
+	assert.Equal("${workspace}", attributes["tags"].(map[string]interface{})["workspace"], "Workspace name was not replaced in nested attributes")
+	assert.Equal("rg-abc123", testStruct.State.Resources[0].Instances[0].RawAttributes["name"], "Replacing the workspace name changed the State")
+}
+
+func TestGoldenComparisonFindsDifferences(t *testing.T) {
+	assert := assert.New(t)
+	testStruct, _ := SetupMockSnapshot(t)
+	golden := testStruct.goldenState(nil)
+	golden["azurerm_resource_group.example"][0].Attributes["name"] = "old-rg"
+	delete(golden, "azurerm_storage_account.this")
+
+	differences := diffSnapshots(testStruct.goldenSnapshot(golden), testStruct.goldenSnapshot(testStruct.goldenState(nil))).Descriptions()
+
+	assert.Equal([]string{
+		"azurerm_storage_account.this[alpha] (added)",
+		"azurerm_storage_account.this[gamma] (added)",
+		"azurerm_resource_group.example (changed): name",
+	}, differences, "Differences from the golden file were not found")
+}
+
+func TestCompileAttributePatternMatchesSegments(t *testing.T) {
+	assert := assert.New(t)
+	matcher := compileAttributePattern("network_rules.*.ip_*")
+
+	assert.True(matcher.MatchString("network_rules.0.ip_rules"), "Wildcard segment did not match")
+	assert.True(matcher.MatchString("network_rules.0.ip_rules.1"), "Nested attributes were not matched")
+	assert.False(matcher.MatchString("network_rules.0.bypass"), "Wildcard matched a different attribute")
+	assert.False(matcher.MatchString("network_rules.0.1.ip_rules"), "Wildcard crossed a segment")
+}
+
+func TestGoldenFileNeverContainsSensitiveValues(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromFile("testdata/sensitive.tfstate")
+	path := filepath.Join(t.TempDir(), "sensitive.json")
+
+	t.Setenv("UPDATE_GOLDEN", "true")
+	testStruct.AssertStateMatchesGolden(path)
+	t.Setenv("UPDATE_GOLDEN", "")
+
+	content, err := os.ReadFile(path)
+	assert.NoError(err, "Golden file was not written")
+	assert.NotContains(string(content), "c3VwZXItc2VjcmV0LWFjY2Vzcy1rZXk=", "Sensitive value was written to the golden file")
+	assert.NotContains(string(content), "10.0.0.1", "Nested sensitive value was written to the golden file")
+	assert.Contains(string(content), `"primary_access_key": "(sensitive value)"`, "Sensitive value was not replaced by the placeholder")
+	assert.Contains(string(content), `"default_action": "Deny"`, "Attributes next to a sensitive attribute were masked")
+}
+
+func TestGoldenComparisonComparesSensitiveValuesAsPlaceholders(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromFile("testdata/sensitive.tfstate")
+	path := filepath.Join(t.TempDir(), "sensitive.json")
+
+	t.Setenv("UPDATE_GOLDEN", "true")
+	testStruct.AssertStateMatchesGolden(path)
+	t.Setenv("UPDATE_GOLDEN", "")
+	testStruct.State.Resources[0].Instances[0].RawAttributes["primary_access_key"] = "cm90YXRlZC1rZXk="
+
+	assert.True(testStruct.AssertStateMatchesGolden(path), "Rotated secret failed the comparison")
+	assert.Equal("cm90YXRlZC1rZXk=", testStruct.State.Resources[0].Instances[0].RawAttributes["primary_access_key"], "Masking changed the State")
+}
@@ -0,0 +1,327 @@
+package terratestPlus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/mednax-it/terratest-plus/deployment"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	DependencyGraph is the graph of resources in the State, built from the `dependencies` recorded on each instance.
+
+Nodes are resource addresses in the cleaned format used by the Find functions. An edge from a to b means a depends on b.
+*/
+type DependencyGraph struct {
+	nodes map[string]bool
+	edges map[string]map[string]bool
+}
+
+/*
+	DependencyGraph builds the DependencyGraph of the current State.
+
+Terraform records dependencies without module keys (`module.platforms.azurerm_x.y`), so a dependency on a resource inside a module with count or for_each is linked to the instances of that module matching the keys of the dependent resource, or to every instance if none match.
+*/
+func (d *Deployment) DependencyGraph() *DependencyGraph {
+	graph := &DependencyGraph{nodes: make(map[string]bool), edges: make(map[string]map[string]bool)}
+
+	byConfig := make(map[string][]deployment.Address)
+	for _, resource := range d.State.Resources {
+		address := d.resourceAddress(resource)
+		byConfig[address.ConfigAddress()] = append(byConfig[address.ConfigAddress()], address)
+		graph.nodes[address.CleanString()] = true
+	}
+
+	for _, resource := range d.State.Resources {
+		address := d.resourceAddress(resource)
+		for _, instance := range resource.Instances {
+			for _, dependency := range instance.Dependencies {
+				for _, target := range resolveDependency(address, dependency, byConfig) {
+					graph.addEdge(address.CleanString(), target.CleanString())
+				}
+			}
+		}
+	}
+	return graph
+}
+
+/*
+ResolveDependency finds the resources a recorded dependency refers to, preferring those in the same module instances as the dependent resource.
+
+Any module step recorded with a key must match exactly, at every level of nesting. Steps recorded without a key prefer the key of the dependent resource.
+*/
+func resolveDependency(from deployment.Address, dependency string, byConfig map[string][]deployment.Address) []deployment.Address {
+	parsed, err := deployment.ParseAddress(dependency)
+	if err != nil {
+		return nil
+	}
+
+	candidates := make([]deployment.Address, 0)
+	for _, candidate := range byConfig[parsed.ConfigAddress()] {
+		if moduleKeysMatchRecorded(parsed.Module, candidate.Module) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	preferred := withRecordedKeys(from.Module, parsed.Module)
+	sameModule := make([]deployment.Address, 0)
+	for _, candidate := range candidates {
+		if moduleKeysCompatible(preferred, candidate.Module) {
+			sameModule = append(sameModule, candidate)
+		}
+	}
+	if len(sameModule) > 0 {
+		return sameModule
+	}
+	return candidates
+}
+
+/*
+ModuleKeysCompatible is true when every module step the two paths share (by position and name) has the same key.
+*/
+func moduleKeysCompatible(from []deployment.ModuleStep, to []deployment.ModuleStep) bool {
+	for i := 0; i < len(from) && i < len(to); i++ {
+		if from[i].Name != to[i].Name {
+			return true
+		}
+		if fmt.Sprint(from[i].Key) != fmt.Sprint(to[i].Key) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+ModuleKeysMatchRecorded is true when every step of the recorded module path that has a key has the same key in the module path.
+*/
+func moduleKeysMatchRecorded(recorded []deployment.ModuleStep, module []deployment.ModuleStep) bool {
+	if len(recorded) != len(module) {
+		return false
+	}
+	for i, step := range recorded {
+		if step.Key != nil && fmt.Sprint(step.Key) != fmt.Sprint(module[i].Key) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+WithRecordedKeys copies the module path of the dependent resource, replacing its keys with any key the recorded dependency has for the same module step.
+*/
+func withRecordedKeys(from []deployment.ModuleStep, recorded []deployment.ModuleStep) []deployment.ModuleStep {
+	module := append([]deployment.ModuleStep{}, from...)
+	for i := 0; i < len(module) && i < len(recorded); i++ {
+		if module[i].Name != recorded[i].Name {
+			break
+		}
+		if recorded[i].Key != nil {
+			module[i].Key = recorded[i].Key
+		}
+	}
+	return module
+}
+
+func (g *DependencyGraph) addEdge(from string, to string) {
+	if from == to {
+		return
+	}
+	g.nodes[from] = true
+	g.nodes[to] = true
+	if g.edges[from] == nil {
+		g.edges[from] = make(map[string]bool)
+	}
+	g.edges[from][to] = true
+}
+
+// Nodes returns every resource address in the graph, sorted.
+func (g *DependencyGraph) Nodes() []string {
+	return sortedKeys(g.nodes)
+}
+
+// Dependencies returns the sorted addresses the resource directly depends on.
+func (g *DependencyGraph) Dependencies(address string) []string {
+	return sortedKeys(g.edges[cleanAddressString(address)])
+}
+
+// Dependents returns the sorted addresses that directly depend on the resource.
+func (g *DependencyGraph) Dependents(address string) []string {
+	address = cleanAddressString(address)
+	dependents := make(map[string]bool)
+	for from, targets := range g.edges {
+		if targets[address] {
+			dependents[from] = true
+		}
+	}
+	return sortedKeys(dependents)
+}
+
+/*
+DependsOn is true when resource a depends on resource b, either directly or through other resources.
+*/
+func (g *DependencyGraph) DependsOn(a string, b string) bool {
+	a, b = cleanAddressString(a), cleanAddressString(b)
+	visited := make(map[string]bool)
+	queue := []string{a}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for target := range g.edges[current] {
+			if target == b {
+				return true
+			}
+			if !visited[target] {
+				visited[target] = true
+				queue = append(queue, target)
+			}
+		}
+	}
+	return false
+}
+
+/*
+	TopologicalOrder returns every resource with its dependencies before it - the order terraform creates them in.
+
+Resources with no ordering between them are sorted by address so the result is stable. Returns an error naming the resources involved if the graph has a cycle.
+*/
+func (g *DependencyGraph) TopologicalOrder() ([]string, error) {
+	remaining := make(map[string]int)
+	for node := range g.nodes {
+		remaining[node] = len(g.edges[node])
+	}
+
+	order := make([]string, 0, len(g.nodes))
+	for len(remaining) > 0 {
+		ready := make([]string, 0)
+		for node, count := range remaining {
+			if count == 0 {
+				ready = append(ready, node)
+			}
+		}
+		if len(ready) == 0 {
+			return order, fmt.Errorf("dependency cycle between %v", sortedKeys(remaining))
+		}
+		sort.Strings(ready)
+
+		for _, node := range ready {
+			delete(remaining, node)
+			order = append(order, node)
+			for dependent := range remaining {
+				if g.edges[dependent][node] {
+					remaining[dependent]--
+				}
+			}
+		}
+	}
+	return order, nil
+}
+
+/*
+DOT renders the graph in the graphviz DOT language, with an arrow from each resource to what it depends on (the same direction as `terraform graph`).
+*/
+func (g *DependencyGraph) DOT() string {
+	var dot strings.Builder
+	dot.WriteString("digraph {\n")
+	for _, node := range g.Nodes() {
+		fmt.Fprintf(&dot, "\t%q;\n", node)
+	}
+	for _, from := range g.Nodes() {
+		for _, to := range sortedKeys(g.edges[from]) {
+			fmt.Fprintf(&dot, "\t%q -> %q;\n", from, to)
+		}
+	}
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+/*
+Mermaid renders the graph as a Mermaid flowchart, with an arrow from each resource to what it depends on.
+*/
+func (g *DependencyGraph) Mermaid() string {
+	ids := make(map[string]string)
+	var mermaid strings.Builder
+	mermaid.WriteString("flowchart LR\n")
+	for i, node := range g.Nodes() {
+		ids[node] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&mermaid, "\t%s[\"%s\"]\n", ids[node], node)
+	}
+	for _, from := range g.Nodes() {
+		for _, to := range sortedKeys(g.edges[from]) {
+			fmt.Fprintf(&mermaid, "\t%s --> %s\n", ids[from], ids[to])
+		}
+	}
+	return mermaid.String()
+}
+
+/*
+DependsOn is true when resource a depends on resource b in the State, either directly or through other resources.
+*/
+func (d *Deployment) DependsOn(a string, b string) bool {
+	return d.DependencyGraph().DependsOn(a, b)
+}
+
+// Dependents returns the sorted addresses in the State that directly depend on the resource.
+func (d *Deployment) Dependents(address string) []string {
+	return d.DependencyGraph().Dependents(address)
+}
+
+/*
+TopologicalOrder returns every resource in the State with its dependencies before it. Fails the test if the graph has a cycle.
+*/
+func (d *Deployment) TopologicalOrder() []string {
+	order, err := d.DependencyGraph().TopologicalOrder()
+	assert.NoError(d.T, err)
+	return order
+}
+
+/*
+AssertDependsOn fails the test if resource a does not depend on resource b, e.g. that a private endpoint depends on its DNS zone link.
+*/
+func (d *Deployment) AssertDependsOn(a string, b string) bool {
+	graph := d.DependencyGraph()
+	return assert.Truef(d.T, graph.DependsOn(a, b), "%s does not depend on %s. Its direct dependencies are %v", a, b, graph.Dependencies(a))
+}
+
+/*
+	WriteDependencyGraph writes the graph of the State to a file for use as a test artifact, e.g. when a test has failed.
+
+Files ending in `.mmd` or `.md` are written as Mermaid, anything else as DOT.
+*/
+func (d *Deployment) WriteDependencyGraph(path string) {
+	graph := d.DependencyGraph()
+
+	content := graph.DOT()
+	if extension := filepath.Ext(path); extension == ".mmd" || extension == ".md" {
+		content = graph.Mermaid()
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		logger.Logf(d.T, "Error writing dependency graph to %s: %v", path, err)
+	}
+}
+
+/*
+CleanAddressString turns an address in any format into the cleaned resource address used as graph nodes, leaving it as is if it cannot be parsed.
+*/
+func cleanAddressString(address string) string {
+	parsed, err := deployment.ParseAddress(address)
+	if err != nil {
+		return address
+	}
+	return parsed.ResourceAddress().CleanString()
+}
+
+// sortedKeys returns the keys of a map, sorted.
+func sortedKeys[V any](set map[string]V) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
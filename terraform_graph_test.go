@@ -0,0 +1,125 @@
+package terratestPlus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var MockModuleDependencyState string = `{
+	"resources": [
+		{"mode": "managed", "type": "azurerm_resource_group", "name": "example", "instances": [{"attributes": {}}]},
+		{"module": "module.platforms[\"alpha\"]", "mode": "managed", "type": "azurerm_private_dns_zone_virtual_network_link", "name": "this", "instances": [{"attributes": {}, "dependencies": ["azurerm_resource_group.example"]}]},
+		{"module": "module.platforms[\"beta\"]", "mode": "managed", "type": "azurerm_private_dns_zone_virtual_network_link", "name": "this", "instances": [{"attributes": {}, "dependencies": ["azurerm_resource_group.example"]}]},
+		{"module": "module.platforms[\"alpha\"]", "mode": "managed", "type": "azurerm_private_endpoint", "name": "this", "instances": [{"attributes": {}, "dependencies": ["azurerm_resource_group.example", "module.platforms.azurerm_private_dns_zone_virtual_network_link.this"]}]}
+	]
+}`
+
+func SetupMockDependencyState(t *testing.T) *Deployment {
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromBytes([]byte(MockModuleDependencyState))
+	return testStruct
+}
+
+func TestDependencyGraphFromLoadedState(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	dependents := testStruct.Dependents("azurerm_resource_group.example")
+
+	assert.Equal([]string{"azurerm_storage_account.example", "module.platforms[alpha].azurerm_storage_account.this", "module.platforms[beta].azurerm_storage_account.this"}, dependents, "Dependents were not found from the recorded dependencies")
+	assert.True(testStruct.DependsOn(`module.platforms["alpha"].azurerm_storage_account.this`, "azurerm_resource_group.example"), "DependsOn did not accept the terraform address format")
+	assert.False(testStruct.DependsOn("azurerm_resource_group.example", "azurerm_storage_account.example"), "DependsOn was true in the wrong direction")
+}
+
+func TestDependencyGraphResolvesModuleDependenciesToTheSameInstance(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockDependencyState(t)
+
+	dependencies := testStruct.DependencyGraph().Dependencies("module.platforms[alpha].azurerm_private_endpoint.this")
+
+	assert.Equal([]string{"azurerm_resource_group.example", "module.platforms[alpha].azurerm_private_dns_zone_virtual_network_link.this"}, dependencies, "Module dependency was not resolved to the same module instance")
+}
+
+var MockNestedModuleDependencyState string = `{
+	"resources": [
+		{"module": "module.platforms[\"alpha\"].module.network[0]", "mode": "managed", "type": "azurerm_subnet", "name": "this", "instances": [{"attributes": {}}]},
+		{"module": "module.platforms[\"alpha\"].module.network[1]", "mode": "managed", "type": "azurerm_subnet", "name": "this", "instances": [{"attributes": {}}]},
+		{"module": "module.platforms[\"beta\"].module.network[0]", "mode": "managed", "type": "azurerm_subnet", "name": "this", "instances": [{"attributes": {}}]},
+		{"module": "module.platforms[\"alpha\"].module.network[1]", "mode": "managed", "type": "azurerm_private_endpoint", "name": "this", "instances": [{"attributes": {}, "dependencies": ["module.platforms.module.network.azurerm_subnet.this"]}]},
+		{"module": "module.platforms[\"beta\"].module.network[0]", "mode": "managed", "type": "azurerm_private_endpoint", "name": "this", "instances": [{"attributes": {}, "dependencies": ["module.platforms[\"alpha\"].module.network.azurerm_subnet.this"]}]},
+		{"module": "module.platforms[\"alpha\"].module.network[0]", "mode": "managed", "type": "azurerm_network_interface", "name": "this", "instances": [{"attributes": {}, "dependencies": ["module.platforms.module.network[1].azurerm_subnet.this"]}]}
+	]
+}`
+
+func TestDependencyGraphResolvesNestedModuleDependencies(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromBytes([]byte(MockNestedModuleDependencyState))
+	graph := testStruct.DependencyGraph()
+
+	assert.Equal([]string{"module.platforms[alpha].module.network[1].azurerm_subnet.this"}, graph.Dependencies("module.platforms[alpha].module.network[1].azurerm_private_endpoint.this"), "Unkeyed nested dependency was not resolved to the same module instances")
+	assert.Equal([]string{"module.platforms[alpha].module.network[0].azurerm_subnet.this"}, graph.Dependencies("module.platforms[beta].module.network[0].azurerm_private_endpoint.this"), "Outer module key was not matched for a nested dependency")
+	assert.Equal([]string{"module.platforms[alpha].module.network[1].azurerm_subnet.this"}, graph.Dependencies("module.platforms[alpha].module.network[0].azurerm_network_interface.this"), "Inner module key was not matched for a nested dependency")
+}
+
+func TestDependsOnIsTransitive(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockDependencyState(t)
+	graph := testStruct.DependencyGraph()
+	graph.edges["module.platforms[alpha].azurerm_private_endpoint.this"] = map[string]bool{"module.platforms[alpha].azurerm_private_dns_zone_virtual_network_link.this": true}
+
+	assert.True(graph.DependsOn("module.platforms[alpha].azurerm_private_endpoint.this", "azurerm_resource_group.example"), "DependsOn did not follow the graph")
+}
+
+func TestTopologicalOrderPutsDependenciesFirst(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockDependencyState(t)
+
+	order := testStruct.TopologicalOrder()
+
+	assert.Equal([]string{
+		"azurerm_resource_group.example",
+		"module.platforms[alpha].azurerm_private_dns_zone_virtual_network_link.this",
+		"module.platforms[beta].azurerm_private_dns_zone_virtual_network_link.this",
+		"module.platforms[alpha].azurerm_private_endpoint.this",
+	}, order, "Dependencies were not ordered first")
+}
+
+func TestTopologicalOrderReportsCycles(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockDependencyState(t)
+	graph := testStruct.DependencyGraph()
+	graph.addEdge("azurerm_resource_group.example", "module.platforms[alpha].azurerm_private_endpoint.this")
+
+	_, err := graph.TopologicalOrder()
+
+	assert.ErrorContains(err, "dependency cycle", "Cycle was not reported")
+}
+
+func TestDependencyGraphRendersDOTAndMermaid(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockDependencyState(t)
+	graph := testStruct.DependencyGraph()
+
+	dot := graph.DOT()
+	mermaid := graph.Mermaid()
+
+	assert.True(strings.HasPrefix(dot, "digraph {"), "DOT output did not start with a digraph")
+	assert.Contains(dot, `"module.platforms[alpha].azurerm_private_endpoint.this" -> "azurerm_resource_group.example";`, "DOT edge was not rendered")
+	assert.True(strings.HasPrefix(mermaid, "flowchart LR"), "Mermaid output did not start with a flowchart")
+	assert.Contains(mermaid, `n0["azurerm_resource_group.example"]`, "Mermaid node was not rendered")
+	assert.Contains(mermaid, "n1 --> n0", "Mermaid edge was not rendered")
+}
+
+func TestAssertDependsOnPasses(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockDependencyState(t)
+
+	passed := testStruct.AssertDependsOn("module.platforms[alpha].azurerm_private_endpoint.this", "module.platforms[alpha].azurerm_private_dns_zone_virtual_network_link.this")
+
+	assert.True(passed, "Direct dependency was not found")
+}
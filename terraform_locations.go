@@ -0,0 +1,50 @@
+package terratestPlus
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	AssertLocations fails the test if any managed instance in the State has a `location` outside the allowed locations, listing every out of policy instance with its location.
+
+Locations are compared with NormaliseLocation, so `East US`, `eastus` and `EastUS` are the same location. Instances without a location are skipped. Resources that Azure places in the `global` location (such as DNS zones) need `global` to be allowed.
+*/
+func (d *Deployment) AssertLocations(allowed ...string) bool {
+	outOfPolicy := d.locationsOutsideOf(allowed)
+	return assert.Emptyf(d.T, outOfPolicy, "Resources found outside of the allowed locations %v:\n%s", allowed, strings.Join(outOfPolicy, "\n"))
+}
+
+/*
+NormaliseLocation turns any spelling of an Azure location into its short name, e.g. `East US` into `eastus`.
+*/
+func NormaliseLocation(location string) string {
+	return strings.Map(func(char rune) rune {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) {
+			return unicode.ToLower(char)
+		}
+		return -1
+	}, location)
+}
+
+/*
+LocationsOutsideOf describes every managed instance with a location that is not allowed, sorted by address.
+*/
+func (d *Deployment) locationsOutsideOf(allowed []string) []string {
+	allowedLocations := make(map[string]bool)
+	for _, location := range allowed {
+		allowedLocations[NormaliseLocation(location)] = true
+	}
+
+	outOfPolicy := make([]string, 0)
+	for _, instance := range d.Query().Mode("managed").Instances() {
+		location, _ := instance.Attributes()["location"].(string)
+		if location != "" && !allowedLocations[NormaliseLocation(location)] {
+			outOfPolicy = append(outOfPolicy, fmt.Sprintf("%s (%s)", instance.Address.CleanString(), location))
+		}
+	}
+	return outOfPolicy
+}
@@ -0,0 +1,31 @@
+package terratestPlus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormaliseLocationMatchesSpellings(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("eastus", NormaliseLocation("East US"), "Display name was not normalised")
+	assert.Equal("eastus2", NormaliseLocation("East US 2"), "Location with a number was not normalised")
+	assert.Equal("westeurope", NormaliseLocation("westeurope"), "Short name was changed")
+}
+
+func TestLocationsOutsideOfAllowedLocations(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	outOfPolicy := testStruct.locationsOutsideOf([]string{"East US"})
+
+	assert.Equal([]string{"module.platforms[beta].azurerm_storage_account.this[0] (westeurope)"}, outOfPolicy, "Out of policy location was not found")
+}
+
+func TestAssertLocationsPassesForAllowedLocations(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	assert.True(testStruct.AssertLocations("eastus", "West Europe"), "Allowed locations failed the test")
+}
@@ -0,0 +1,153 @@
+package terratestPlus
+
+import (
+	"sort"
+
+	"github.com/mednax-it/terratest-plus/deployment"
+)
+
+/*
+	ModuleInstance is one instance of a module in the State, such as `module.platforms[alpha]`, built by Deployment.Modules().
+
+Path is the cleaned module path ("" for the root module) and Key is the count index or for_each key (nil when the module has neither).
+
+Resources holds the resources directly in this module instance, keyed like the Find functions, including resources without any instances. Children are sorted by Path.
+*/
+type ModuleInstance struct {
+	Path      string
+	Name      string
+	Key       interface{}
+	Children  []*ModuleInstance
+	Resources map[string]*deployment.StateResource
+}
+
+/*
+	Modules returns the tree of module instances in the State, starting from the root module.
+
+e.g. to test that module.platforms was created once per entry of a tfvars map:
+
+	keys := d.Modules().InstanceKeys("platforms")
+*/
+func (d *Deployment) Modules() *ModuleInstance {
+	root := newModuleInstance("", "", nil)
+	for _, resource := range d.State.Resources {
+		address := d.resourceAddress(resource)
+
+		current := root
+		for i, step := range address.Module {
+			path := deployment.Address{Module: address.Module[:i+1]}.CleanModulePath()
+			current = current.child(path, step)
+		}
+		current.Resources[address.CleanString()] = resource
+	}
+
+	root.sortChildren()
+	return root
+}
+
+/*
+	FindByModule finds the resources directly in a module instance, such as `module.platforms[alpha]` (or `module.platforms["alpha"]`). Use an empty string for the root module.
+
+`*` wildcards are supported as in FindByAddress, so `module.platforms[*]` finds the resources of every instance of module.platforms.
+
+The return has the same shape and keys as FindAllResourceType. Resources without any instances (such as `count = 0`) are included.
+*/
+func (d *Deployment) FindByModule(path string) map[string]*deployment.StateResource {
+	matcher := compileAddressPattern(path)
+
+	output := make(map[string]*deployment.StateResource)
+	for _, resource := range d.State.Resources {
+		address := d.resourceAddress(resource)
+		if matcher.MatchString(address.CleanModulePath()) {
+			output[address.CleanString()] = resource
+		}
+	}
+	return output
+}
+
+/*
+Find returns the module instance at the path (in either the terraform or cleaned format) below this one, or nil if there is none.
+*/
+func (m *ModuleInstance) Find(path string) *ModuleInstance {
+	path = normaliseModuleAddress(path)
+	if m.Path == path {
+		return m
+	}
+
+	for _, child := range m.Children {
+		if found := child.Find(path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+/*
+	InstanceKeys returns the sorted keys of every direct child instance of the module name (without the `module.` prefix).
+
+For_each keys are returned as they are, count indexes as their string form. A module without count or for_each returns a single empty key.
+*/
+func (m *ModuleInstance) InstanceKeys(name string) []string {
+	keys := make([]string, 0)
+	for _, child := range m.Children {
+		if child.Name != name {
+			continue
+		}
+
+		if child.Key == nil {
+			keys = append(keys, "")
+		} else {
+			keys = append(keys, deployment.InstanceKeyString(child.Key))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+/*
+	LocalAddresses returns the sorted addresses of the resources directly in this module instance, relative to the module (e.g. `azurerm_storage_account.this`).
+
+As they are relative, they can be compared between instances of the same module to check each contains the expected resource set.
+*/
+func (m *ModuleInstance) LocalAddresses() []string {
+	addresses := make([]string, 0, len(m.Resources))
+	for _, resource := range m.Resources {
+		mode, _ := resource.Mode.(string)
+		resourceType, _ := resource.Type.(string)
+		name, _ := resource.Name.(string)
+		addresses = append(addresses, deployment.Address{Mode: mode, Type: resourceType, Name: name}.CleanString())
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+func newModuleInstance(path string, name string, key interface{}) *ModuleInstance {
+	return &ModuleInstance{
+		Path:      path,
+		Name:      name,
+		Key:       key,
+		Children:  make([]*ModuleInstance, 0),
+		Resources: make(map[string]*deployment.StateResource),
+	}
+}
+
+func (m *ModuleInstance) child(path string, step deployment.ModuleStep) *ModuleInstance {
+	for _, child := range m.Children {
+		if child.Path == path {
+			return child
+		}
+	}
+
+	child := newModuleInstance(path, step.Name, step.Key)
+	m.Children = append(m.Children, child)
+	return child
+}
+
+func (m *ModuleInstance) sortChildren() {
+	sort.Slice(m.Children, func(i, j int) bool {
+		return m.Children[i].Path < m.Children[j].Path
+	})
+	for _, child := range m.Children {
+		child.sortChildren()
+	}
+}
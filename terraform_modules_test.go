@@ -0,0 +1,96 @@
+package terratestPlus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var MockNestedModuleState string = `{
+	"resources": [
+		{"mode": "managed", "type": "azurerm_resource_group", "name": "example", "instances": [{"attributes": {}}]},
+		{"module": "module.platforms[\"alpha\"]", "mode": "managed", "type": "azurerm_storage_account", "name": "this", "instances": [{"attributes": {}}]},
+		{"module": "module.platforms[\"alpha\"]", "mode": "data", "type": "azurerm_subscription", "name": "current", "instances": [{"attributes": {}}]},
+		{"module": "module.platforms[\"beta\"]", "mode": "managed", "type": "azurerm_storage_account", "name": "this", "instances": [{"attributes": {}}]},
+		{"module": "module.platforms[\"beta\"].module.network[0]", "mode": "managed", "type": "azurerm_subnet", "name": "this", "instances": [{"attributes": {}}]},
+		{"module": "module.platforms[\"beta\"].module.network[1]", "mode": "managed", "type": "azurerm_subnet", "name": "this", "instances": [{"attributes": {}}]},
+		{"module": "module.shared.module.dns", "mode": "managed", "type": "azurerm_private_dns_zone", "name": "this", "instances": [{"attributes": {}}]}
+	]
+}`
+
+func SetupMockModuleState(t *testing.T) *Deployment {
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromBytes([]byte(MockNestedModuleState))
+	return testStruct
+}
+
+func TestModulesBuildsTheTree(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockModuleState(t)
+
+	root := testStruct.Modules()
+
+	assert.Equal("", root.Path, "Root module should have an empty path")
+	assert.Contains(root.Resources, "azurerm_resource_group.example", "Root resources were not added to the root module")
+	assert.Equal(3, len(root.Children), "Incorrect number of module instances below the root")
+	assert.Equal("module.platforms[alpha]", root.Children[0].Path, "Children were not sorted by path")
+	assert.Equal("alpha", root.Children[0].Key, "Module key was not set")
+}
+
+func TestModulesCreatesModulesWithoutResources(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockModuleState(t)
+
+	shared := testStruct.Modules().Find("module.shared")
+
+	assert.NotNil(shared, "Module with only child modules was not created")
+	assert.Empty(shared.Resources, "Module without resources has resources")
+	assert.NotNil(shared.Find("module.shared.module.dns"), "Nested module was not found")
+}
+
+func TestModuleInstanceKeys(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockModuleState(t)
+	root := testStruct.Modules()
+
+	assert.Equal([]string{"alpha", "beta"}, root.InstanceKeys("platforms"), "for_each keys were not found")
+	assert.Equal([]string{"0", "1"}, root.Find(`module.platforms["beta"]`).InstanceKeys("network"), "count indexes were not found")
+	assert.Equal([]string{""}, root.InstanceKeys("shared"), "Module without keys did not return a single empty key")
+}
+
+func TestModuleLocalAddressesCanBeCompared(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockModuleState(t)
+	root := testStruct.Modules()
+
+	assert.Equal([]string{"azurerm_storage_account.this", "data.azurerm_subscription.current"}, root.Find("module.platforms[alpha]").LocalAddresses(), "Local addresses were not relative to the module")
+	assert.Equal([]string{"azurerm_storage_account.this"}, root.Find("module.platforms[beta]").LocalAddresses(), "Child module resources were included in the local addresses")
+}
+
+func TestFindByModule(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockModuleState(t)
+
+	alpha := testStruct.FindByModule(`module.platforms["alpha"]`)
+	everyNetwork := testStruct.FindByModule("module.platforms[*].module.network[*]")
+
+	assert.Equal(2, len(alpha), "Resources directly in the module were not found")
+	assert.Contains(alpha, "module.platforms[alpha].data.azurerm_subscription.current", "Key was not the same as FindAllResourceType")
+	assert.Equal(2, len(everyNetwork), "Wildcard module path did not find every instance")
+}
+
+func TestFindByModuleIncludesResourcesWithoutInstances(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromBytes([]byte(`{"resources": [
+		{"module": "module.app", "mode": "managed", "type": "azurerm_storage_account", "name": "this", "instances": [{"attributes": {}}]},
+		{"module": "module.app", "mode": "managed", "type": "azurerm_private_endpoint", "name": "this", "instances": []}
+	]}`))
+
+	found := testStruct.FindByModule("module.app")
+
+	assert.Contains(found, "module.app.azurerm_private_endpoint.this", "Resource with count = 0 was not found")
+	assert.Equal([]string{"azurerm_private_endpoint.this", "azurerm_storage_account.this"}, testStruct.Modules().Find("module.app").LocalAddresses(), "Resource with count = 0 was not in the module tree")
+}
@@ -0,0 +1,137 @@
+package terratestPlus
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	NamingRules maps resource types to the regex pattern the `name` of every instance of that type must match, such as
+
+	NamingRules{"azurerm_resource_group": `(?P<env>\w+)-(?P<app>\w+)-rg`}
+
+Patterns must match the whole name. Named groups (`?P<name>`) are captured into the NamingResult of each instance, so they can be checked against the var file with AssertNamingGroupsMatchVars.
+*/
+type NamingRules map[string]string
+
+/*
+NamingResult is the outcome of checking the name of a single instance against its NamingRules pattern.
+*/
+type NamingResult struct {
+	Address string
+	Type    string
+	Name    string
+	Matched bool
+	Groups  map[string]string
+}
+
+/*
+	EvaluateNamingRules checks the name of every managed instance whose type has a rule, returning a NamingResult for each sorted by address.
+
+Returns an error listing every pattern that does not compile, without checking any names. Instances without a `name` attribute never match.
+*/
+func (d *Deployment) EvaluateNamingRules(rules NamingRules) ([]NamingResult, error) {
+	compiled, err := rules.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]NamingResult, 0)
+	for _, instance := range d.Query().Mode("managed").Instances() {
+		pattern, hasRule := compiled[instance.Address.Type]
+		if !hasRule {
+			continue
+		}
+
+		result := NamingResult{Address: instance.Address.CleanString(), Type: instance.Address.Type, Groups: make(map[string]string)}
+		name, hasName := instance.Attributes()["name"].(string)
+		if match := pattern.FindStringSubmatch(name); hasName && match != nil {
+			result.Matched = true
+			for i, group := range pattern.SubexpNames() {
+				if i > 0 && group != "" {
+					result.Groups[group] = match[i]
+				}
+			}
+		}
+		result.Name = name
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+/*
+	AssertNamingRules fails the test if any pattern does not compile, or if the name of any instance does not match the pattern of its type, listing every failing name.
+
+The results are returned so the captured groups can be cross-checked, e.g. with AssertNamingGroupsMatchVars.
+*/
+func (d *Deployment) AssertNamingRules(rules NamingRules) []NamingResult {
+	results, err := d.EvaluateNamingRules(rules)
+	if err != nil {
+		assert.Fail(d.T, "Invalid naming rules", err.Error())
+		return results
+	}
+
+	failures := make([]string, 0)
+	for _, result := range results {
+		if !result.Matched {
+			failures = append(failures, fmt.Sprintf("%s name %q does not match %s", result.Address, result.Name, rules[result.Type]))
+		}
+	}
+	assert.Emptyf(d.T, failures, "Names do not follow the naming rules:\n%s", strings.Join(failures, "\n"))
+	return results
+}
+
+/*
+	AssertNamingGroupsMatchVars fails the test if a captured group of any matched result differs from a variable in the var file, listing every mismatch.
+
+groupVars maps group names to variable names, e.g. `map[string]string{"env": "environment"}` checks the `env` group of every name against `VarFileValues["environment"]`. Results without the group are skipped.
+*/
+func (d *Deployment) AssertNamingGroupsMatchVars(results []NamingResult, groupVars map[string]string) bool {
+	groups := sortedKeys(groupVars)
+
+	mismatches := make([]string, 0)
+	for _, result := range results {
+		for _, group := range groups {
+			captured, hasGroup := result.Groups[group]
+			if !hasGroup {
+				continue
+			}
+
+			variable := groupVars[group]
+			value, hasVar := d.VarFileValues[variable]
+			if !hasVar {
+				mismatches = append(mismatches, fmt.Sprintf("%s group %s: variable %s is not in the var file", result.Address, group, variable))
+			} else if fmt.Sprint(value) != captured {
+				mismatches = append(mismatches, fmt.Sprintf("%s group %s is %q but variable %s is %q", result.Address, group, captured, variable, fmt.Sprint(value)))
+			}
+		}
+	}
+
+	return assert.Emptyf(d.T, mismatches, "Names do not match the var file:\n%s", strings.Join(mismatches, "\n"))
+}
+
+/*
+Compile compiles every pattern anchored to the whole name, collecting every compile error rather than stopping at the first.
+*/
+func (r NamingRules) compile() (map[string]*regexp.Regexp, error) {
+	compiled := make(map[string]*regexp.Regexp)
+	errors := make([]string, 0)
+	for resourceType, pattern := range r {
+		// compiled as given first, so errors show the pattern as it was written
+		if _, err := regexp.Compile(pattern); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", resourceType, err))
+			continue
+		}
+		compiled[resourceType] = regexp.MustCompile(`^(?:` + pattern + `)$`)
+	}
+
+	if len(errors) > 0 {
+		sort.Strings(errors)
+		return nil, fmt.Errorf("naming rules do not compile: %s", strings.Join(errors, "; "))
+	}
+	return compiled, nil
+}
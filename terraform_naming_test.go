@@ -0,0 +1,58 @@
+package terratestPlus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var MockNamingRules NamingRules = NamingRules{
+	"azurerm_resource_group":  `(?P<prefix>[A-Z]+)-(?P<app>\w+)-(?P<env>\w+)`,
+	"azurerm_storage_account": `(?P<app>[a-z]+)sa(?P<index>\d+)`,
+}
+
+func TestEvaluateNamingRulesCapturesGroups(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	results, err := testStruct.EvaluateNamingRules(MockNamingRules)
+
+	assert.NoError(err)
+	assert.Equal(4, len(results), "Every instance with a rule was not checked")
+	assert.Equal("azurerm_resource_group.example", results[0].Address, "Results were not sorted by address")
+	assert.Equal(map[string]string{"prefix": "TEMP", "app": "DeleteMe", "env": "Testing"}, results[0].Groups, "Named groups were not captured")
+	assert.Equal(map[string]string{"app": "alpha", "index": "0"}, results[2].Groups, "Named groups were not captured for module instances")
+}
+
+func TestEvaluateNamingRulesMatchesWholeName(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	results, _ := testStruct.EvaluateNamingRules(NamingRules{"azurerm_storage_account": `[a-z]+sa`})
+
+	for _, result := range results {
+		assert.Falsef(result.Matched, "%s matched a pattern for only part of its name", result.Name)
+		assert.Empty(result.Groups, "Groups were captured for a name that did not match")
+	}
+}
+
+func TestEvaluateNamingRulesReportsEveryCompileError(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	_, err := testStruct.EvaluateNamingRules(NamingRules{"azurerm_resource_group": `(?P<env>\w+`, "azurerm_storage_account": `[a-z`, "azurerm_subnet": `\w+`})
+
+	assert.ErrorContains(err, "azurerm_resource_group", "Compile error of the first rule was not reported")
+	assert.ErrorContains(err, "azurerm_storage_account", "Compile error of the second rule was not reported")
+	assert.NotContains(err.Error(), "azurerm_subnet", "Valid rule was reported as an error")
+}
+
+func TestAssertNamingRulesAndGroupsPass(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+	testStruct.VarFileValues = map[string]interface{}{"environment": "Testing", "application": "DeleteMe"}
+
+	results := testStruct.AssertNamingRules(NamingRules{"azurerm_resource_group": MockNamingRules["azurerm_resource_group"]})
+
+	assert.True(testStruct.AssertNamingGroupsMatchVars(results, map[string]string{"env": "environment", "app": "application"}), "Groups matching the var file failed the test")
+}
@@ -0,0 +1,79 @@
+package terratestPlus
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/mednax-it/terratest-plus/deployment"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	GetOutput decodes the output into T, such as a string, []string, map[string]string or a struct with `json` tags.
+
+The outputs come from `terraform output -json` after DeployInfrastructure (or from the State when loaded with LoadStateFromFile), so the declared output type is kept.
+
+Fails the test if the output does not exist or cannot be decoded into T. The value of a sensitive output is never included in the failure message.
+*/
+func GetOutput[T any](d *Deployment, name string) T {
+	var value T
+	output := d.getOutput(name)
+
+	raw, err := json.Marshal(output.Value)
+	if err == nil {
+		err = json.Unmarshal(raw, &value)
+	}
+
+	if err != nil {
+		if output.Sensitive {
+			assert.FailNow(d.T, "Unable to decode output", "Sensitive output %s could not be decoded into %T", name, value)
+		}
+		assert.FailNow(d.T, "Unable to decode output", "Output %s could not be decoded into %T: %v", name, value, err)
+	}
+	return value
+}
+
+/*
+	GetOutputPath returns the value at a path inside an object or list output, using the same path syntax as GetInstanceAttributeValuesOfResource (e.g. `subnets.0.id`).
+
+If the path fans out with `*`, every value found is returned as a []interface{}.
+
+Fails the test if the output or the path does not exist, naming the missing path segment (never the value).
+*/
+func (d *Deployment) GetOutputPath(name string, path string) interface{} {
+	output := d.getOutput(name)
+
+	values, err := lookupAttributePath(output.Value, path)
+	if err != nil {
+		assert.FailNow(d.T, "Unable to find output path", "Unable to find %s in output %s: %v", path, name, err)
+	}
+
+	if strings.Contains(path, "*") {
+		return values
+	}
+	return values[0]
+}
+
+/*
+IsOutputSensitive returns whether the output was declared sensitive. Fails the test if the output does not exist.
+*/
+func (d *Deployment) IsOutputSensitive(name string) bool {
+	return d.getOutput(name).Sensitive
+}
+
+/*
+GetOutput returns the typed output, failing the test if it does not exist.
+*/
+func (d *Deployment) getOutput(name string) *deployment.StateOutput {
+	output, ok := d.Outputs[name]
+	if !ok || output == nil {
+		names := make([]string, 0, len(d.Outputs))
+		for outputName := range d.Outputs {
+			names = append(names, outputName)
+		}
+		sort.Strings(names)
+		assert.FailNow(d.T, "Output not found", "Output %s was not found. Available outputs: %v", name, names)
+	}
+	return output
+}
@@ -0,0 +1,66 @@
+package terratestPlus
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mednax-it/terratest-plus/deployment"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockSubnet struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestLoadedStateSetsOutputs(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	assert.Equal(3, len(testStruct.Outputs), "Outputs were not set from the State")
+	assert.Equal("TEMP-DeleteMe-Testing", testStruct.OutputValues["resource_group_name"], "OutputValues were not set from the State")
+}
+
+func TestGetOutputDecodesTypes(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	resourceGroup := GetOutput[string](testStruct, "resource_group_name")
+	subnets := GetOutput[[]MockSubnet](testStruct, "subnets")
+
+	assert.Equal("TEMP-DeleteMe-Testing", resourceGroup, "String output was not decoded")
+	assert.Equal("data", subnets[1].Name, "List of objects output was not decoded")
+}
+
+func TestGetOutputPath(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	name := testStruct.GetOutputPath("subnets", "0.name")
+	names := testStruct.GetOutputPath("subnets", "*.name")
+
+	assert.Equal("app", name, "Single value was not returned for the path")
+	assert.Equal([]interface{}{"app", "data"}, names, "Fanned out values were not returned for the path")
+}
+
+func TestSensitiveOutputsAreMasked(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+	output := testStruct.Outputs["storage_account_key"]
+
+	assert.True(testStruct.IsOutputSensitive("storage_account_key"), "Sensitive flag was not kept")
+	assert.False(testStruct.IsOutputSensitive("resource_group_name"), "Non sensitive output was flagged sensitive")
+	assert.Equal("(sensitive value)", fmt.Sprintf("%v", output), "Sensitive value was printed by %v")
+	assert.Equal("(sensitive value)", fmt.Sprintf("%#v", output), "Sensitive value was printed by %#v")
+	assert.NotContains(fmt.Sprintf("%v", testStruct.Outputs), "c2VjcmV0LWtleQ==", "Sensitive value was printed with the map of Outputs")
+	assert.Equal("c2VjcmV0LWtleQ==", GetOutput[string](testStruct, "storage_account_key"), "Sensitive value was not decoded")
+}
+
+func TestSensitiveOutputValuesAreMasked(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	assert.Equal(deployment.SensitiveValue, testStruct.OutputValues["storage_account_key"], "Sensitive value was stored in OutputValues")
+	assert.NotContains(fmt.Sprintf("%v", testStruct.OutputValues), "c2VjcmV0LWtleQ==", "Sensitive value was printed with the map of OutputValues")
+	assert.Equal("c2VjcmV0LWtleQ==", GetOutput[string](testStruct, "storage_account_key"), "Sensitive value was not available from GetOutput")
+}
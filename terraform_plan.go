@@ -0,0 +1,332 @@
+package terratestPlus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	test_structure "github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/mednax-it/terratest-plus/deployment"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	PlanInfrastructure will perform Terraform Init and Terraform Plan with the options that were set in SetupTerraform. Nothing is applied.
+
+Init is controlled the same way as DeployInfrastructure (RunInit flag and SKIP_terraform_init)
+
+Plan is controlled by the Test Structure Stage variable SKIP_terraform_plan
+
+The resulting plan is stored in the Plan (and RawPlan) vars for testing what *would* be changed.
+*/
+func (d *Deployment) PlanInfrastructure() {
+
+	d.initWorkspace()
+
+	test_structure.RunTestStage(d.T, "terraform_plan", func() {
+		d.GetPlan()
+	})
+}
+
+/*
+GetPlan runs `terraform plan -out` with the configured TerraformOptions, converts the plan file with `terraform show -json` and sets the Plan and RawPlan vars.
+
+The plan file is written to a temp directory that is removed at the end of the test.
+*/
+func (d *Deployment) GetPlan() {
+	options := d.planOptions()
+
+	_, err := terraform.PlanE(d.T, options)
+	if err != nil {
+		logger.Logf(d.T, "Error running Plan: %v", err)
+		assert.FailNow(d.T, "Terraform Plan failed - tests cannot run.")
+	}
+
+	tf_show_plan, err := terraform.ShowE(d.T, options)
+	if err != nil {
+		logger.Logf(d.T, "Error showing Plan file: %v", err)
+		assert.FailNow(d.T, "Plan File was not able to be converted to json - tests cannot run.")
+	}
+
+	d.LoadPlanFromBytes([]byte(tf_show_plan))
+}
+
+/*
+LoadPlanFromFile reads the json of a plan (the output of `terraform show -json <planfile>`) from disk and loads it the same way GetPlan does.
+*/
+func (d *Deployment) LoadPlanFromFile(path string) {
+	raw_plan, err := os.ReadFile(path)
+
+	if err != nil {
+		logger.Logf(d.T, "Error reading Plan file %s: %v", path, err)
+		assert.FailNow(d.T, "Plan File was not able to be read - tests cannot run.")
+	}
+
+	d.LoadPlanFromBytes(raw_plan)
+}
+
+/*
+	LoadPlanFromBytes takes the json of a plan and sets the Plan and RawPlan vars from it.
+
+As with the State, the module_address of each resource change is cleaned unless KeepRawModuleAddresses is set.
+*/
+func (d *Deployment) LoadPlanFromBytes(raw_plan []byte) {
+	d.Plan, d.RawPlan = d.parsePlan(raw_plan)
+}
+
+/*
+ParsePlan builds the typed and raw versions of the json of a plan, cleaning the module addresses unless KeepRawModuleAddresses is set.
+*/
+func (d *Deployment) parsePlan(raw_plan []byte) (*deployment.TerraformPlan, map[string]interface{}) {
+	plan := new(deployment.TerraformPlan)
+	var raw map[string]interface{}
+
+	err := json.Unmarshal(raw_plan, &raw)
+	if err == nil {
+		err = json.Unmarshal(raw_plan, plan)
+	}
+
+	if err != nil {
+		logger.Logf(d.T, "Error building Plan Map: %v", err)
+		assert.FailNow(d.T, "Could not build map of Plan File - Tests cannot run.")
+	}
+
+	if !d.KeepRawModuleAddresses {
+		for _, change := range append(plan.ResourceChanges, plan.ResourceDrift...) {
+			change.ModuleAddress = normaliseModuleAddress(change.ModuleAddress)
+		}
+	}
+
+	return plan, raw
+}
+
+/*
+	AssertIdempotent runs `terraform plan -detailed-exitcode` against the current deployment and fails the test if anything would change again.
+
+This catches perpetual diffs, such as tags normalised by the provider. The failure lists every address that would change along with the attributes that differ.
+
+This should be run after Apply. It can be run automatically as part of DeployInfrastructure by setting CheckIdempotency in SetupTerraformOptions.
+
+The second plan is stored in the Plan (and RawPlan) vars.
+*/
+func (d *Deployment) AssertIdempotent() bool {
+	options := d.planOptions()
+
+	exitCode, err := terraform.PlanExitCodeE(d.T, options)
+	if err != nil || (exitCode != terraform.DefaultSuccessExitCode && exitCode != terraform.TerraformPlanChangesPresentExitCode) {
+		logger.Logf(d.T, "Error running Plan (exit code %d): %v", exitCode, err)
+		return assert.Fail(d.T, "Terraform Plan failed - idempotency could not be checked.")
+	}
+
+	if exitCode == terraform.DefaultSuccessExitCode {
+		return true
+	}
+
+	tf_show_plan, err := terraform.ShowE(d.T, options)
+	if err != nil {
+		logger.Logf(d.T, "Error showing Plan file: %v", err)
+		return assert.Fail(d.T, "Plan File was not able to be converted to json - idempotency could not be checked.")
+	}
+	d.LoadPlanFromBytes([]byte(tf_show_plan))
+
+	return assert.Failf(d.T, "Deployment is not idempotent", "A second plan after apply would still change:\n\t%s", strings.Join(describePlanChanges(d.Plan), "\n\t"))
+}
+
+/*
+DescribePlanChanges returns a readable line per resource change that is not a no-op or a read, e.g. `azurerm_x.y (update): tags`.
+*/
+func describePlanChanges(plan *deployment.TerraformPlan) []string {
+	descriptions := make([]string, 0)
+	for _, change := range plan.ResourceChanges {
+		actions := strings.Join(change.Change.Actions, ", ")
+		if actions == "no-op" || actions == "read" {
+			continue
+		}
+
+		description := fmt.Sprintf("%s (%s)", change.Address, actions)
+		if attributes := changedAttributes(change.Change); len(attributes) > 0 {
+			description += ": " + strings.Join(attributes, ", ")
+		}
+		descriptions = append(descriptions, description)
+	}
+	return descriptions
+}
+
+/*
+ChangedAttributes returns the sorted top level attribute names that differ between Before and After, or that are unknown until apply.
+*/
+func changedAttributes(change *deployment.PlanChange) []string {
+	before, _ := change.Before.(map[string]interface{})
+	after, _ := change.After.(map[string]interface{})
+	unknown, _ := change.AfterUnknown.(map[string]interface{})
+
+	changed := make(map[string]bool)
+	for key, value := range before {
+		if !reflect.DeepEqual(value, after[key]) {
+			changed[key] = true
+		}
+	}
+	for key, value := range after {
+		if !reflect.DeepEqual(value, before[key]) {
+			changed[key] = true
+		}
+	}
+	for key, value := range unknown {
+		if value == true {
+			changed[key] = true
+		}
+	}
+
+	attributes := make([]string, 0, len(changed))
+	for key := range changed {
+		attributes = append(attributes, key)
+	}
+	sort.Strings(attributes)
+	return attributes
+}
+
+/*
+	AssertPlanCreates fails the test if the Plan does not create the resource at the address.
+
+The address uses the same cleaned module.type.name format as the keys returned by FindAllResourceType. The full instance address (e.g. `azurerm_x.y[0]`) is also accepted.
+
+Like all the AssertPlan functions, this uses the Plan var - if no plan has been loaded yet, GetPlan is run against the configured TerraformOptions.
+*/
+func (d *Deployment) AssertPlanCreates(address string) bool {
+	return d.assertPlanAction(address, "create")
+}
+
+/*
+AssertPlanUpdates fails the test if the Plan does not update the resource at the address in place.
+*/
+func (d *Deployment) AssertPlanUpdates(address string) bool {
+	return d.assertPlanAction(address, "update")
+}
+
+/*
+AssertPlanReplaces fails the test if the Plan does not replace (destroy and re-create, in either order) the resource at the address.
+*/
+func (d *Deployment) AssertPlanReplaces(address string) bool {
+	return d.assertPlanAction(address, "replace")
+}
+
+/*
+AssertPlanDestroys fails the test if the Plan does not destroy the resource at the address.
+*/
+func (d *Deployment) AssertPlanDestroys(address string) bool {
+	return d.assertPlanAction(address, "delete")
+}
+
+/*
+AssertPlanDestroysNothing fails the test if the Plan destroys anything - including replacements - listing every address that would be destroyed.
+*/
+func (d *Deployment) AssertPlanDestroysNothing() bool {
+	destroyed := make([]string, 0)
+	for _, change := range d.getLoadedPlan().ResourceChanges {
+		action := planAction(change)
+		if action == "delete" || action == "replace" {
+			destroyed = append(destroyed, change.Address+" ("+action+")")
+		}
+	}
+
+	return assert.Emptyf(d.T, destroyed, "Plan destroys resources: %v", destroyed)
+}
+
+/*
+	AssertPlanActionCounts fails the test if the Plan does not have exactly the number of creates, updates and deletes.
+
+The counts follow the terraform `Plan: X to add, Y to change, Z to destroy.` summary, so a replacement counts as one create and one delete.
+*/
+func (d *Deployment) AssertPlanActionCounts(create int, update int, delete int) bool {
+	counts := make(map[string]int)
+	for _, change := range d.getLoadedPlan().ResourceChanges {
+		if change.Mode != "managed" {
+			continue
+		}
+
+		switch planAction(change) {
+		case "replace":
+			counts["create"]++
+			counts["delete"]++
+		default:
+			counts[planAction(change)]++
+		}
+	}
+
+	return assert.Equalf(d.T, []int{create, update, delete}, []int{counts["create"], counts["update"], counts["delete"]}, "Plan action counts [create, update, delete] did not match")
+}
+
+/*
+AssertPlanAction checks that at least one change for the address has the action, failing with the actions that were found instead.
+*/
+func (d *Deployment) assertPlanAction(address string, action string) bool {
+	found := make([]string, 0)
+	for _, change := range d.planChangesFor(address) {
+		if planAction(change) == action {
+			return true
+		}
+		found = append(found, planAction(change))
+	}
+
+	if len(found) == 0 {
+		return assert.Failf(d.T, "Plan action not found", "Expected %s of %s but the address is not in the Plan", action, address)
+	}
+	return assert.Failf(d.T, "Plan action not found", "Expected %s of %s but the Plan has %v", action, address, found)
+}
+
+/*
+PlanChangesFor returns the resource changes in the Plan matching the address, either as the cleaned module.type.name or as the full instance address.
+*/
+func (d *Deployment) planChangesFor(address string) []*deployment.PlanResourceChange {
+	found := make([]*deployment.PlanResourceChange, 0)
+	for _, change := range d.getLoadedPlan().ResourceChanges {
+		changeAddress, err := deployment.ParseAddress(change.Address)
+		if err != nil {
+			continue
+		}
+
+		if changeAddress.ResourceAddress().CleanString() == address || changeAddress.CleanString() == address || change.Address == address {
+			found = append(found, change)
+		}
+	}
+	return found
+}
+
+/*
+PlanAction collapses the action list of a change into one of create, read, update, delete, replace or no-op.
+*/
+func planAction(change *deployment.PlanResourceChange) string {
+	actions := change.Change.Actions
+	if len(actions) == 2 {
+		return "replace"
+	}
+	if len(actions) == 1 {
+		return actions[0]
+	}
+	return "no-op"
+}
+
+/*
+GetLoadedPlan returns the Plan, running GetPlan first if no plan has been loaded.
+*/
+func (d *Deployment) getLoadedPlan() *deployment.TerraformPlan {
+	if d.Plan == nil {
+		d.GetPlan()
+	}
+	return d.Plan
+}
+
+/*
+PlanOptions returns a copy of the TerraformOptions with a PlanFilePath set, so the plan file does not leak into the options used for Apply and Destroy.
+*/
+func (d *Deployment) planOptions() *terraform.Options {
+	options := d.TerraformOptions
+	options.PlanFilePath = filepath.Join(d.T.TempDir(), "terratest-plus.tfplan")
+	return &options
+}
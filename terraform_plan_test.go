@@ -0,0 +1,122 @@
+package terratestPlus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func SetupMockPlan(t *testing.T) *Deployment {
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadPlanFromFile("testdata/plan.json")
+	return testStruct
+}
+
+func TestLoadPlanFromFileBuildsResourceChanges(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+	expectedLength := 6
+
+	assert.Equalf(expectedLength, len(testStruct.Plan.ResourceChanges), "Incorrect number [%d] of resource changes loaded (should be %d)", len(testStruct.Plan.ResourceChanges), expectedLength)
+	assert.Equal("1.7.5", testStruct.Plan.TerraformVersion, "Plan terraform_version was not parsed")
+}
+
+func TestLoadPlanFromFileBuildsTypedChanges(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+
+	change := testStruct.Plan.ResourceChanges[4]
+
+	assert.Equal(`module.platforms["gamma"].azurerm_storage_account.this[0]`, change.Address, "Resource change address was not parsed")
+	assert.Equal([]string{"create"}, change.Change.Actions, "Resource change actions were not parsed")
+	assert.Nil(change.Change.Before, "Before of a create should be nil")
+	assert.Equal("gammasa0", change.Change.After.(map[string]interface{})["name"], "Resource change after was not parsed")
+	assert.Equal(true, change.Change.AfterUnknown.(map[string]interface{})["id"], "Resource change after_unknown was not parsed")
+}
+
+func TestLoadPlanFromFileNormalisesModuleAddress(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+
+	assert.Equal("module.platforms[gamma]", testStruct.Plan.ResourceChanges[4].ModuleAddress, "Plan module address was not normalised")
+}
+
+func TestLoadPlanFromFileBuildsRawPlan(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+
+	resourceChanges := testStruct.RawPlan["resource_changes"].([]interface{})
+
+	assert.Equal(6, len(resourceChanges), "RawPlan resource_changes were not loaded")
+}
+
+func TestDescribePlanChangesSkipsNoOpAndRead(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+	expectedLength := 4
+
+	descriptions := describePlanChanges(testStruct.Plan)
+
+	assert.Equalf(expectedLength, len(descriptions), "Incorrect number [%d] of changes described (should be %d)", len(descriptions), expectedLength)
+}
+
+func TestDescribePlanChangesListsChangedAttributes(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+
+	descriptions := describePlanChanges(testStruct.Plan)
+
+	assert.Contains(descriptions, "azurerm_storage_account.example (update): tags", "Update description did not list the changed attribute")
+	assert.Contains(descriptions, `module.platforms["alpha"].azurerm_storage_account.this[0] (delete, create): id, location`, "Replace description did not list the changed and unknown attributes")
+}
+
+func TestAssertPlanActionsMatchByCleanedAddress(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+
+	assert.True(testStruct.AssertPlanCreates("module.platforms[gamma].azurerm_storage_account.this"), "Create was not found by cleaned address")
+	assert.True(testStruct.AssertPlanReplaces("module.platforms[alpha].azurerm_storage_account.this"), "Replace was not found by cleaned address")
+}
+
+func TestAssertPlanActionsMatchByFullAddress(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+
+	assert.True(testStruct.AssertPlanUpdates("azurerm_storage_account.example"), "Update was not found by full address")
+	assert.True(testStruct.AssertPlanDestroys("azurerm_storage_account.exampleCount[0]"), "Delete was not found by full address")
+	assert.True(testStruct.AssertPlanCreates(`module.platforms["gamma"].azurerm_storage_account.this[0]`), "Create was not found by terraform address")
+}
+
+func TestPlanChangesForUnknownAddressIsEmpty(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+
+	changes := testStruct.planChangesFor("module.platforms[delta].azurerm_storage_account.this")
+
+	assert.Empty(changes, "Changes were found for an address not in the Plan")
+}
+
+func TestAssertPlanActionCountsFollowsTerraformSummary(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+
+	assert.True(testStruct.AssertPlanActionCounts(2, 1, 2), "Action counts did not follow the terraform summary")
+}
+
+func TestPlanActionCollapsesReplace(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockPlan(t)
+
+	assert.Equal("replace", planAction(testStruct.Plan.ResourceChanges[3]), "Delete and create was not collapsed to replace")
+	assert.Equal("no-op", planAction(testStruct.Plan.ResourceChanges[0]), "No-op was not kept as no-op")
+}
+
+func TestAssertPlanDestroysNothingPassesWithoutDeletes(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadPlanFromBytes([]byte(`{"resource_changes": [{"address": "azurerm_resource_group.example", "mode": "managed", "change": {"actions": ["create"]}}]}`))
+
+	assert.True(testStruct.AssertPlanDestroysNothing(), "Plan without deletes reported destroyed resources")
+}
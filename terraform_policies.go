@@ -0,0 +1,332 @@
+package terratestPlus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/mednax-it/terratest-plus/deployment"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// The severities a Policy can have. Only findings of PolicySeverityError fail the test.
+const (
+	PolicySeverityError   = "error"
+	PolicySeverityWarning = "warning"
+	PolicySeverityInfo    = "info"
+)
+
+/*
+	PolicyFile is a set of policies shipped as data, loaded from YAML or JSON by LoadPolicyFile.
+
+e.g.
+
+	policies:
+	  - name: storage-https-only
+	    type: azurerm_storage_account
+	    attribute: https_traffic_only_enabled
+	    operator: equals
+	    value: true
+	  - name: tls-version
+	    type: azurerm_*
+	    attribute: min_tls_version
+	    operator: in
+	    value: [TLS1_2, TLS1_3]
+	    severity: warning
+*/
+type PolicyFile struct {
+	Policies []*Policy `yaml:"policies" json:"policies"`
+}
+
+/*
+	Policy is a single rule checked against every managed instance of the resource types it selects.
+
+Type selects resource types, where `*` matches any characters (`azurerm_*`, or `*` for every type). Attribute is a path as in GetInstanceAttributeValuesOfResource - when it fans out with `*`, every value found must pass.
+
+Operator is one of
+  - equals: the attribute equals Value
+  - regex: the attribute matches the regex in Value
+  - in: the attribute is one of the list in Value
+  - exists: the attribute is set to something other than null or empty
+  - not_exists: the attribute is missing, null or empty (terraform records many unset attributes as `""` or `[]`)
+
+Severity is error (the default), warning or info.
+*/
+type Policy struct {
+	Name        string      `yaml:"name" json:"name"`
+	Description string      `yaml:"description" json:"description"`
+	Type        string      `yaml:"type" json:"type"`
+	Attribute   string      `yaml:"attribute" json:"attribute"`
+	Operator    string      `yaml:"operator" json:"operator"`
+	Value       interface{} `yaml:"value" json:"value"`
+	Severity    string      `yaml:"severity" json:"severity"`
+
+	typeMatcher *regexp.Regexp
+	valueRegex  *regexp.Regexp
+}
+
+// PolicyFinding is an instance that did not pass a Policy.
+type PolicyFinding struct {
+	Policy   string
+	Severity string
+	Address  string
+	Message  string
+}
+
+func (f PolicyFinding) String() string {
+	return fmt.Sprintf("[%s] %s: %s (%s)", f.Severity, f.Address, f.Message, f.Policy)
+}
+
+/*
+PolicyReport is the outcome of evaluating a PolicyFile, with findings sorted by policy and address. Checked is the number of instance and policy pairs evaluated.
+*/
+type PolicyReport struct {
+	Checked  int
+	Findings []PolicyFinding
+}
+
+// BySeverity returns the findings with the severity.
+func (r *PolicyReport) BySeverity(severity string) []PolicyFinding {
+	found := make([]PolicyFinding, 0)
+	for _, finding := range r.Findings {
+		if finding.Severity == severity {
+			found = append(found, finding)
+		}
+	}
+	return found
+}
+
+// HasErrors is true when any finding has the error severity.
+func (r *PolicyReport) HasErrors() bool {
+	return len(r.BySeverity(PolicySeverityError)) > 0
+}
+
+/*
+	LoadPolicyFile reads and validates a policy file. Files ending in `.json` are parsed as JSON, anything else as YAML.
+
+Returns an error listing every invalid policy, such as an unknown operator or a regex that does not compile.
+*/
+func LoadPolicyFile(path string) (*PolicyFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := new(PolicyFile)
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(content, policies)
+	} else {
+		err = yaml.Unmarshal(content, policies)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse policy file %s: %v", path, err)
+	}
+
+	if err := policies.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %v", path, err)
+	}
+	return policies, nil
+}
+
+/*
+	EvaluatePolicies loads the policy file at the path and checks every policy against the managed instances in RawState, returning the report.
+
+Deposed objects are skipped. Every finding is logged, but only findings with the error severity fail the test. A policy file that cannot be loaded fails the test immediately.
+
+Values of an instance's `sensitive_attributes` are never included in a finding. Only the instance values are masked - the Value of the policy itself is always printed in the message, so do not put secrets in a policy file.
+*/
+func (d *Deployment) EvaluatePolicies(path string) *PolicyReport {
+	policies, err := LoadPolicyFile(path)
+	if err != nil {
+		logger.Logf(d.T, "Error loading policy file: %v", err)
+		assert.FailNow(d.T, "Policy File was not able to be loaded - policies cannot be evaluated.")
+	}
+
+	report := d.evaluatePolicyFile(policies)
+	for _, finding := range report.Findings {
+		logger.Logf(d.T, "Policy finding %s", finding)
+	}
+
+	errors := make([]string, 0)
+	for _, finding := range report.BySeverity(PolicySeverityError) {
+		errors = append(errors, finding.String())
+	}
+	assert.Emptyf(d.T, errors, "Policies in %s failed:\n%s", path, strings.Join(errors, "\n"))
+	return report
+}
+
+func (d *Deployment) evaluatePolicyFile(policies *PolicyFile) *PolicyReport {
+	report := &PolicyReport{Findings: make([]PolicyFinding, 0)}
+
+	resources, _ := d.RawState["resources"].([]interface{})
+	for _, policy := range policies.Policies {
+		for _, v := range resources {
+			resource, _ := v.(map[string]interface{})
+			resourceType, _ := resource["type"].(string)
+			if resource["mode"] != "managed" || !policy.typeMatcher.MatchString(resourceType) {
+				continue
+			}
+
+			address := d.resourceAddress(resource)
+			instances, _ := resource["instances"].([]interface{})
+			for _, inst_val := range instances {
+				instance, _ := inst_val.(map[string]interface{})
+				if deposed, _ := instance["deposed"].(string); deposed != "" {
+					continue
+				}
+
+				attributes, _ := instance["attributes"].(map[string]interface{})
+				sensitiveAttributes, _ := instance["sensitive_attributes"].([]interface{})
+				sensitive := deployment.SensitivePaths(sensitiveAttributes)
+				address.Key = instance["index_key"]
+
+				report.Checked++
+				if message := policy.check(attributes, attributePathsOverlap(policy.Attribute, sensitive)); message != "" {
+					report.Findings = append(report.Findings, PolicyFinding{Policy: policy.Name, Severity: policy.Severity, Address: address.CleanString(), Message: message})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		if report.Findings[i].Policy != report.Findings[j].Policy {
+			return report.Findings[i].Policy < report.Findings[j].Policy
+		}
+		return report.Findings[i].Address < report.Findings[j].Address
+	})
+	return report
+}
+
+/*
+Validate checks every policy, setting defaults and compiling its patterns. Returns an error listing every invalid policy.
+*/
+func (f *PolicyFile) validate() error {
+	problems := make([]string, 0)
+	for i, policy := range f.Policies {
+		if policy.Name == "" {
+			policy.Name = fmt.Sprintf("policy %d", i+1)
+		}
+		if policy.Severity == "" {
+			policy.Severity = PolicySeverityError
+		}
+
+		if err := policy.compile(); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", policy.Name, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (p *Policy) compile() error {
+	if p.Type == "" || p.Attribute == "" {
+		return fmt.Errorf("type and attribute are required")
+	}
+	if p.Severity != PolicySeverityError && p.Severity != PolicySeverityWarning && p.Severity != PolicySeverityInfo {
+		return fmt.Errorf("unknown severity %q", p.Severity)
+	}
+	p.typeMatcher = regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(p.Type), `\*`, ".*") + "$")
+
+	switch p.Operator {
+	case "equals", "exists", "not_exists":
+	case "regex":
+		pattern, isString := p.Value.(string)
+		if !isString {
+			return fmt.Errorf("regex value must be a string")
+		}
+		expression, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		p.valueRegex = expression
+	case "in":
+		if _, isList := p.Value.([]interface{}); !isList {
+			return fmt.Errorf("in value must be a list")
+		}
+	default:
+		return fmt.Errorf("unknown operator %q", p.Operator)
+	}
+	return nil
+}
+
+/*
+Check describes how the attributes fail the policy, or returns an empty string if they pass. When sensitive is true, attribute values are replaced by deployment.SensitiveValue in the description.
+*/
+func (p *Policy) check(attributes map[string]interface{}, sensitive bool) string {
+	values, err := lookupAttributePath(attributes, p.Attribute)
+	set := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		if !isEmptyAttribute(value) {
+			set = append(set, value)
+		}
+	}
+
+	switch p.Operator {
+	case "exists":
+		if err != nil || len(set) == 0 {
+			return fmt.Sprintf("%s is not set", p.Attribute)
+		}
+		return ""
+	case "not_exists":
+		if err == nil && len(set) > 0 {
+			return fmt.Sprintf("%s is set to %v", p.Attribute, maskValue(set, sensitive))
+		}
+		return ""
+	}
+
+	if err != nil {
+		return fmt.Sprintf("%s not found: %v", p.Attribute, err)
+	}
+	for _, value := range values {
+		if !p.passes(value) {
+			return fmt.Sprintf("%s is %v, expected %s %v", p.Attribute, maskValue(value, sensitive), p.Operator, p.Value)
+		}
+	}
+	return ""
+}
+
+func (p *Policy) passes(value interface{}) bool {
+	switch p.Operator {
+	case "equals":
+		return attributeValuesEqual(value, p.Value)
+	case "regex":
+		return value != nil && p.valueRegex.MatchString(fmt.Sprint(value))
+	case "in":
+		for _, allowed := range p.Value.([]interface{}) {
+			if attributeValuesEqual(value, allowed) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func maskValue(value interface{}, sensitive bool) interface{} {
+	if sensitive {
+		return deployment.SensitiveValue
+	}
+	return value
+}
+
+func isEmptyAttribute(value interface{}) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case string:
+		return typed == ""
+	case []interface{}:
+		return len(typed) == 0
+	case map[string]interface{}:
+		return len(typed) == 0
+	}
+	return false
+}
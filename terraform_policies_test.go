@@ -0,0 +1,158 @@
+package terratestPlus
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func WriteMockPolicyFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPolicyFileSetsDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	policies, err := LoadPolicyFile("testdata/policies.yaml")
+
+	assert.NoError(err)
+	assert.Equal(4, len(policies.Policies), "Every policy was not loaded")
+	assert.Equal(PolicySeverityError, policies.Policies[0].Severity, "Severity did not default to error")
+	assert.Equal([]interface{}{"GRS", "LRS", "ZRS"}, policies.Policies[0].Value, "List value was not loaded")
+}
+
+func TestLoadPolicyFileReportsEveryInvalidPolicy(t *testing.T) {
+	assert := assert.New(t)
+	path := WriteMockPolicyFile(t, "policies.json", `{"policies": [
+		{"name": "bad-operator", "type": "azurerm_*", "attribute": "name", "operator": "contains"},
+		{"name": "bad-regex", "type": "azurerm_*", "attribute": "name", "operator": "regex", "value": "[a-z"},
+		{"name": "bad-severity", "type": "azurerm_*", "attribute": "name", "operator": "exists", "severity": "critical"},
+		{"name": "bad-in", "type": "azurerm_*", "attribute": "name", "operator": "in", "value": "eastus"}
+	]}`)
+
+	_, err := LoadPolicyFile(path)
+
+	for _, name := range []string{"bad-operator", "bad-regex", "bad-severity", "bad-in"} {
+		assert.ErrorContainsf(err, name, "Invalid policy %s was not reported", name)
+	}
+}
+
+func TestEvaluatePolicyFileFindsEveryFinding(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+	policies, _ := LoadPolicyFile("testdata/policies.yaml")
+
+	report := testStruct.evaluatePolicyFile(policies)
+
+	assert.Equal(3+1+3+4, report.Checked, "Every selected instance was not checked")
+	assert.False(report.HasErrors(), "Passing error policies reported errors: %v", report.Findings)
+	assert.Equal([]PolicyFinding{{
+		Policy:   "storage-standard-tier",
+		Severity: PolicySeverityWarning,
+		Address:  "module.platforms[beta].azurerm_storage_account.this[0]",
+		Message:  "account_tier is Premium, expected equals Standard",
+	}}, report.BySeverity(PolicySeverityWarning), "Warning finding was not reported")
+	assert.Equal(3, len(report.BySeverity(PolicySeverityInfo)), "Missing attributes were not reported for exists")
+}
+
+func TestPolicyOperators(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+	path := WriteMockPolicyFile(t, "policies.yml", `
+policies:
+  - name: lowercase-location
+    type: "*"
+    attribute: location
+    operator: regex
+    value: ^[a-z]+$
+  - name: public-access
+    type: azurerm_storage_account
+    attribute: public_network_access_enabled
+    operator: not_exists
+`)
+	policies, _ := LoadPolicyFile(path)
+
+	report := testStruct.evaluatePolicyFile(policies)
+
+	addresses := make([]string, 0)
+	for _, finding := range report.BySeverity(PolicySeverityError) {
+		addresses = append(addresses, finding.Policy+" "+finding.Address)
+	}
+	assert.Contains(addresses, "lowercase-location module.platforms[alpha].azurerm_storage_account.this[0]", "Regex did not find a location with spaces")
+	assert.NotContains(addresses, "lowercase-location azurerm_client_config.current", "Data source was checked")
+	assert.NotContains(addresses, "lowercase-location azurerm_resource_group.example", "Matching location was reported")
+}
+
+func TestEvaluatePoliciesPassesWithoutErrors(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	report := testStruct.EvaluatePolicies("testdata/policies.yaml")
+
+	assert.Equal(4, len(report.Findings), "Warning and info findings were not returned")
+}
+
+func TestPolicyFindingsNeverContainSensitiveValues(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromFile("testdata/sensitive.tfstate")
+	path := WriteMockPolicyFile(t, "policies.yaml", `
+policies:
+  - name: access-key
+    type: azurerm_storage_account
+    attribute: primary_access_key
+    operator: regex
+    value: ^rotated-
+  - name: no-connection-string
+    type: azurerm_storage_account
+    attribute: primary_connection_string
+    operator: not_exists
+  - name: no-ip-rules
+    type: azurerm_storage_account
+    attribute: network_rules.*.ip_rules.*
+    operator: in
+    value: [192.168.0.1]
+  - name: tls
+    type: azurerm_storage_account
+    attribute: min_tls_version
+    operator: equals
+    value: TLS1_3
+`)
+	policies, _ := LoadPolicyFile(path)
+
+	report := testStruct.evaluatePolicyFile(policies)
+
+	assert.Equal(4, len(report.Findings), "Policies on sensitive attributes were not evaluated")
+	for _, finding := range report.Findings {
+		assert.NotContains(finding.String(), "c3VwZXItc2VjcmV0LWFjY2Vzcy1rZXk=", "Sensitive value was printed in a finding")
+		assert.NotContains(finding.String(), "10.0.0.1", "Nested sensitive value was printed in a finding")
+	}
+	assert.Contains(report.Findings[0].Message, "(sensitive value)", "Sensitive value was not replaced by the placeholder")
+	assert.Contains(report.Findings[3].Message, "TLS1_2", "Value of an attribute that is not sensitive was masked")
+}
+
+func TestEvaluatePolicyFileSkipsDeposedObjects(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockDeposedState(t)
+	path := WriteMockPolicyFile(t, "policies.yaml", `
+policies:
+  - name: eastus-only
+    type: azurerm_storage_account
+    attribute: location
+    operator: equals
+    value: eastus
+`)
+	policies, _ := LoadPolicyFile(path)
+
+	report := testStruct.evaluatePolicyFile(policies)
+
+	assert.Equal(1, report.Checked, "Deposed object was checked")
+	assert.Empty(report.Findings, "Deposed object was reported")
+}
@@ -0,0 +1,159 @@
+package terratestPlus
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	version "github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/mednax-it/terratest-plus/deployment"
+	"github.com/stretchr/testify/assert"
+)
+
+// The name terraform gives the dependency lock file in the terraform directory
+const providerLockFileName = ".terraform.lock.hcl"
+
+// The default registry of providers given as `namespace/type`
+const defaultProviderRegistry = "registry.terraform.io"
+
+var providerSourceRegex = regexp.MustCompile(`provider\["([^"]+)"\]`)
+
+/*
+	ProviderLockReport compares the providers in the lock file with those recorded on the resources in the State.
+
+Unused are locked providers no resource in the State uses. Unlocked are providers used in the State but missing from the lock file. Built in providers (such as `terraform.io/builtin/terraform`) are never locked, so they are never reported.
+*/
+type ProviderLockReport struct {
+	Unused   []string
+	Unlocked []string
+}
+
+type lockFile struct {
+	Providers []*deployment.ProviderLock `hcl:"provider,block"`
+	Remain    hcl.Body                   `hcl:",remain"`
+}
+
+/*
+	LoadProviderLocks parses the `.terraform.lock.hcl` in the TerraformSourceDir into ProviderLocks, keyed by provider source.
+
+The lock file is written by terraform init, so this should be run after init. The provider assertions run it automatically if ProviderLocks has not been loaded yet.
+*/
+func (d *Deployment) LoadProviderLocks() {
+	d.LoadProviderLocksFromFile(filepath.Join(d.TerraformSourceDir, providerLockFileName))
+}
+
+/*
+LoadProviderLocksFromFile parses a dependency lock file at the path into ProviderLocks, keyed by provider source.
+*/
+func (d *Deployment) LoadProviderLocksFromFile(path string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logger.Logf(d.T, "Error reading lock file %s: %v", path, err)
+		assert.FailNow(d.T, "Lock File was not able to be read - provider versions cannot be checked.")
+	}
+
+	parsed, diagnostics := hclparse.NewParser().ParseHCL(content, path)
+	locks := new(lockFile)
+	if !diagnostics.HasErrors() {
+		diagnostics = gohcl.DecodeBody(parsed.Body, nil, locks)
+	}
+	if diagnostics.HasErrors() {
+		logger.Logf(d.T, "Error parsing lock file %s: %v", path, diagnostics)
+		assert.FailNow(d.T, "Lock File was not able to be parsed - provider versions cannot be checked.")
+	}
+
+	d.ProviderLocks = make(map[string]*deployment.ProviderLock)
+	for _, provider := range locks.Providers {
+		d.ProviderLocks[provider.Source] = provider
+	}
+}
+
+/*
+	AssertProviderVersion fails the test if the version of the provider in the lock file does not satisfy the constraint, or if the provider is not locked at all.
+
+The provider can be the full source (`registry.terraform.io/hashicorp/azurerm`) or the short `hashicorp/azurerm` form. The constraint syntax is the same as the terraform `required_providers` version setting, e.g. `>= 3.90, < 4.0`.
+*/
+func (d *Deployment) AssertProviderVersion(provider string, constraint string) bool {
+	source := providerSource(provider)
+	lock, locked := d.getLoadedProviderLocks()[source]
+	if !locked {
+		return assert.Failf(d.T, "Provider not locked", "Provider %s is not in the lock file. Locked providers are %v", source, sortedKeys(d.ProviderLocks))
+	}
+
+	constraints, err := version.NewConstraint(constraint)
+	if err != nil {
+		return assert.Failf(d.T, "Invalid version constraint", "Could not parse version constraint %s: %v", constraint, err)
+	}
+
+	lockedVersion, err := version.NewVersion(lock.Version)
+	if err != nil {
+		return assert.Failf(d.T, "Invalid provider version", "Could not parse locked version [%s] of %s: %v", lock.Version, source, err)
+	}
+
+	return assert.Truef(d.T, constraints.Check(lockedVersion), "Provider %s is locked at %s which does not satisfy %s", source, lock.Version, constraint)
+}
+
+/*
+ProviderLockReport lists the locked providers no resource in the State uses, and the providers used in the State that are not locked.
+*/
+func (d *Deployment) ProviderLockReport() ProviderLockReport {
+	locks := d.getLoadedProviderLocks()
+	used := d.stateProviderSources()
+
+	report := ProviderLockReport{Unused: make([]string, 0), Unlocked: make([]string, 0)}
+	for _, source := range sortedKeys(locks) {
+		if !used[source] {
+			report.Unused = append(report.Unused, source)
+		}
+	}
+	for _, source := range sortedKeys(used) {
+		if _, locked := locks[source]; !locked && !strings.HasPrefix(source, "terraform.io/builtin/") {
+			report.Unlocked = append(report.Unlocked, source)
+		}
+	}
+	return report
+}
+
+/*
+AssertProvidersLocked fails the test if any provider used by a resource in the State is missing from the lock file.
+*/
+func (d *Deployment) AssertProvidersLocked() bool {
+	unlocked := d.ProviderLockReport().Unlocked
+	return assert.Emptyf(d.T, unlocked, "Providers used in the State are not in the lock file: %v", unlocked)
+}
+
+/*
+StateProviderSources returns the source of every provider recorded on a resource in the State, such as `provider["registry.terraform.io/hashicorp/azurerm"].alias`.
+*/
+func (d *Deployment) stateProviderSources() map[string]bool {
+	sources := make(map[string]bool)
+	for _, resource := range d.State.Resources {
+		provider, _ := resource.Provider.(string)
+		if match := providerSourceRegex.FindStringSubmatch(provider); match != nil {
+			sources[match[1]] = true
+		}
+	}
+	return sources
+}
+
+func (d *Deployment) getLoadedProviderLocks() map[string]*deployment.ProviderLock {
+	if d.ProviderLocks == nil {
+		d.LoadProviderLocks()
+	}
+	return d.ProviderLocks
+}
+
+/*
+ProviderSource expands a `namespace/type` provider to its full source in the default registry, leaving full sources as they are.
+*/
+func providerSource(provider string) string {
+	if strings.Count(provider, "/") == 1 {
+		return defaultProviderRegistry + "/" + provider
+	}
+	return provider
+}
@@ -0,0 +1,62 @@
+package terratestPlus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func SetupMockProviderLocks(t *testing.T) *Deployment {
+	testStruct := SetupMockLoadedState(t)
+	testStruct.TerraformSourceDir = "testdata"
+	testStruct.LoadProviderLocks()
+	return testStruct
+}
+
+func TestLoadProviderLocksParsesLockFile(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockProviderLocks(t)
+
+	lock := testStruct.ProviderLocks["registry.terraform.io/hashicorp/azurerm"]
+
+	assert.Equal(2, len(testStruct.ProviderLocks), "Every provider block was not parsed")
+	assert.Equal("3.116.0", lock.Version, "Locked version was not parsed")
+	assert.Equal(">= 3.90.0, < 4.0.0", lock.Constraints, "Constraints were not parsed")
+	assert.Equal(2, len(lock.Hashes), "Hashes were not parsed")
+	assert.Equal("", testStruct.ProviderLocks["registry.terraform.io/hashicorp/azuread"].Constraints, "Missing constraints were not left empty")
+}
+
+func TestAssertProviderVersionPassesForSatisfiedConstraint(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockProviderLocks(t)
+
+	assert.True(testStruct.AssertProviderVersion("registry.terraform.io/hashicorp/azurerm", ">= 3.90, < 4.0"), "Satisfied constraint failed the test")
+	assert.True(testStruct.AssertProviderVersion("hashicorp/azuread", "~> 2.53"), "Short provider source was not expanded")
+}
+
+func TestAssertProviderVersionLoadsLockFileWhenNeeded(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+	testStruct.TerraformSourceDir = "testdata"
+
+	assert.True(testStruct.AssertProviderVersion("hashicorp/azurerm", ">= 3.0"), "Lock file was not loaded")
+}
+
+func TestProviderLockReportFindsUnusedAndUnlockedProviders(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockProviderLocks(t)
+	testStruct.State.Resources[0].Provider = `module.platforms["alpha"].provider["registry.terraform.io/hashicorp/random"].secondary`
+	testStruct.State.Resources[1].Provider = `provider["terraform.io/builtin/terraform"]`
+
+	report := testStruct.ProviderLockReport()
+
+	assert.Equal([]string{"registry.terraform.io/hashicorp/azuread"}, report.Unused, "Unused locked provider was not reported")
+	assert.Equal([]string{"registry.terraform.io/hashicorp/random"}, report.Unlocked, "Unlocked provider was not reported")
+}
+
+func TestAssertProvidersLockedPassesWhenEveryProviderIsLocked(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockProviderLocks(t)
+
+	assert.True(testStruct.AssertProvidersLocked(), "Locked providers failed the test")
+}
@@ -0,0 +1,214 @@
+package terratestPlus
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/mednax-it/terratest-plus/deployment"
+)
+
+/*
+	ResourceQuery is a chainable set of filters over the instances in the State, started with Deployment.Query().
+
+e.g. `d.Query().Type("azurerm_storage_account").Module("module.app").Mode("managed").WhereAttr("account_tier", "Standard").Instances()`
+
+Every filter is combined (and), and results are always sorted by address so they are stable between runs. Deposed objects are left out unless IncludeDeposed is used.
+*/
+type ResourceQuery struct {
+	d              *Deployment
+	filters        []func(instance QueryInstance) bool
+	includeDeposed bool
+}
+
+/*
+QueryInstance is a single instance found by a ResourceQuery, along with the resource it belongs to and its full instance address.
+*/
+type QueryInstance struct {
+	Address  deployment.Address
+	Resource *deployment.StateResource
+	Instance *deployment.StateResourceInstance
+}
+
+// Attributes returns every attribute of the instance.
+func (q QueryInstance) Attributes() map[string]interface{} {
+	return q.Instance.AttributeMap()
+}
+
+/*
+Query starts a ResourceQuery over every current instance in the State. With no filters added, it returns every instance that is not a deposed object.
+*/
+func (d *Deployment) Query() *ResourceQuery {
+	return &ResourceQuery{d: d}
+}
+
+// Type keeps instances of the resource type, such as `azurerm_storage_account`.
+func (q *ResourceQuery) Type(resourceType string) *ResourceQuery {
+	return q.Where(func(instance QueryInstance) bool {
+		return instance.Address.Type == resourceType
+	})
+}
+
+// Name keeps instances of resources with the terraform name, such as `this`.
+func (q *ResourceQuery) Name(name string) *ResourceQuery {
+	return q.Where(func(instance QueryInstance) bool {
+		return instance.Address.Name == name
+	})
+}
+
+/*
+	Module keeps instances of resources directly in the module path, such as `module.app` or `module.platforms[alpha]`.
+
+Use an empty string for the root module. `*` wildcards are supported as in FindByAddress, e.g. `module.platforms[*]`.
+*/
+func (q *ResourceQuery) Module(path string) *ResourceQuery {
+	matcher := compileAddressPattern(path)
+	return q.Where(func(instance QueryInstance) bool {
+		return matcher.MatchString(instance.Address.CleanModulePath())
+	})
+}
+
+// Mode keeps instances of resources with the mode, `managed` or `data`.
+func (q *ResourceQuery) Mode(mode string) *ResourceQuery {
+	return q.Where(func(instance QueryInstance) bool {
+		return instance.Address.Mode == mode
+	})
+}
+
+/*
+Address keeps instances whose resource or instance address matches the pattern, using the same wildcards as FindByAddress.
+*/
+func (q *ResourceQuery) Address(pattern string) *ResourceQuery {
+	matcher := compileAddressPattern(pattern)
+	return q.Where(func(instance QueryInstance) bool {
+		return matcher.MatchString(instance.Address.CleanString()) || matcher.MatchString(instance.Address.ResourceAddress().CleanString())
+	})
+}
+
+/*
+	WhereAttr keeps instances where the attribute equals the value. The attribute can be a nested path as in GetInstanceAttributeValuesOfResource, matching if any value found at the path is equal.
+
+Numbers are compared by value, so `WhereAttr("node_count", 3)` matches the float64 that json decoding produces.
+*/
+func (q *ResourceQuery) WhereAttr(attribute string, value interface{}) *ResourceQuery {
+	return q.Where(func(instance QueryInstance) bool {
+		found, _ := lookupAttributePath(instance.Attributes(), attribute)
+		for _, foundValue := range found {
+			if attributeValuesEqual(foundValue, value) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+/*
+IncludeDeposed adds the deposed objects left behind by a failed create_before_destroy replacement to the results.
+
+Deposed objects have the same Address as the current instance, so Addresses may repeat an address. Check Instance.IsDeposed() to tell them apart.
+*/
+func (q *ResourceQuery) IncludeDeposed() *ResourceQuery {
+	q.includeDeposed = true
+	return q
+}
+
+// Where keeps instances for which the filter returns true.
+func (q *ResourceQuery) Where(filter func(instance QueryInstance) bool) *ResourceQuery {
+	q.filters = append(q.filters, filter)
+	return q
+}
+
+/*
+Instances runs the query and returns every matching instance, sorted by instance address.
+*/
+func (q *ResourceQuery) Instances() []QueryInstance {
+	found := make([]QueryInstance, 0)
+	for _, resource := range q.d.State.Resources {
+		address := q.d.resourceAddress(resource)
+
+		for _, instance := range resource.Instances {
+			if instance.IsDeposed() && !q.includeDeposed {
+				continue
+			}
+
+			address.Key = instance.IndexKey.Value()
+			candidate := QueryInstance{Address: address, Resource: resource, Instance: instance}
+			if q.matches(candidate) {
+				found = append(found, candidate)
+			}
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Address.CleanString() < found[j].Address.CleanString()
+	})
+	return found
+}
+
+/*
+Addresses runs the query and returns the sorted, cleaned address of every matching instance.
+*/
+func (q *ResourceQuery) Addresses() []string {
+	addresses := make([]string, 0)
+	for _, instance := range q.Instances() {
+		addresses = append(addresses, instance.Address.CleanString())
+	}
+	return addresses
+}
+
+/*
+Resources runs the query and returns every resource with at least one matching instance, sorted by resource address.
+*/
+func (q *ResourceQuery) Resources() []*deployment.StateResource {
+	resources := make([]*deployment.StateResource, 0)
+	seen := make(map[*deployment.StateResource]bool)
+	for _, instance := range q.Instances() {
+		if !seen[instance.Resource] {
+			seen[instance.Resource] = true
+			resources = append(resources, instance.Resource)
+		}
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		return q.d.cleanTerraformAddress(resources[i]) < q.d.cleanTerraformAddress(resources[j])
+	})
+	return resources
+}
+
+// Count runs the query and returns the number of matching instances.
+func (q *ResourceQuery) Count() int {
+	return len(q.Instances())
+}
+
+func (q *ResourceQuery) matches(instance QueryInstance) bool {
+	for _, filter := range q.filters {
+		if !filter(instance) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+AttributeValuesEqual compares two attribute values, treating all number types as equal by value.
+*/
+func attributeValuesEqual(found interface{}, expected interface{}) bool {
+	foundNumber, foundIsNumber := toFloat(found)
+	expectedNumber, expectedIsNumber := toFloat(expected)
+	if foundIsNumber && expectedIsNumber {
+		return foundNumber == expectedNumber
+	}
+	return reflect.DeepEqual(found, expected)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	number := reflect.ValueOf(value)
+	switch number.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(number.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(number.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return number.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,146 @@
+package terratestPlus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func SetupMockLoadedState(t *testing.T) *Deployment {
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+	return testStruct
+}
+
+func TestQueryWithoutFiltersReturnsEveryInstance(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+	expectedLength := 5
+
+	count := testStruct.Query().Count()
+
+	assert.Equalf(expectedLength, count, "Incorrect number [%d] of instances returned (should be %d)", count, expectedLength)
+}
+
+func TestQueryComposesFilters(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	addresses := testStruct.Query().Type("azurerm_storage_account").Module("module.platforms[*]").Mode("managed").WhereAttr("account_tier", "Standard").Addresses()
+
+	assert.Equal([]string{"module.platforms[alpha].azurerm_storage_account.this[0]"}, addresses, "Filters were not combined")
+}
+
+func TestQueryRootModule(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	addresses := testStruct.Query().Module("").Mode("managed").Addresses()
+
+	assert.Equal([]string{"azurerm_resource_group.example", "azurerm_storage_account.example"}, addresses, "Root module filter did not exclude module resources")
+}
+
+func TestQueryNameAvoidsTypeCollisions(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	addresses := testStruct.Query().Name("example").Type("azurerm_resource_group").Addresses()
+
+	assert.Equal([]string{"azurerm_resource_group.example"}, addresses, "Name and Type filters were not combined")
+}
+
+func TestQueryResultsAreSorted(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	addresses := testStruct.Query().Addresses()
+
+	assert.IsNonDecreasing(addresses, "Query results were not sorted")
+}
+
+func TestQueryWhereAttrComparesNumbersByValue(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	count := testStruct.Query().Where(func(instance QueryInstance) bool {
+		return instance.Instance.SchemaVersion == 4
+	}).Count()
+	found := testStruct.Query().WhereAttr("account_tier", "Premium").Resources()
+
+	assert.Equal(3, count, "Where filter was not applied")
+	assert.Equal(1, len(found), "WhereAttr did not find the resource")
+	assert.True(attributeValuesEqual(float64(2), 2), "Numbers of different types were not equal by value")
+}
+
+func TestQueryAddressMatchesInstanceAddresses(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	instances := testStruct.Query().Address("module.platforms[beta].*.*[0]").Instances()
+
+	assert.Equal(1, len(instances), "Address pattern did not match the instance address")
+	assert.Equal("betasa0", instances[0].Attributes()["name"], "Instance attributes were not available")
+}
+
+func TestQueryWorksWithMockState(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.State = SetupMockState()
+
+	count := testStruct.Query().WhereAttr("name", TestAttributeNameTwo).Count()
+
+	assert.Equal(1, count, "WhereAttr did not fall back to the typed Attributes")
+}
+
+var MockDeposedState string = `{
+	"resources": [
+		{"mode": "managed", "type": "azurerm_storage_account", "name": "this", "instances": [
+			{"attributes": {"name": "current", "location": "eastus"}},
+			{"deposed": "00000001", "attributes": {"name": "old", "location": "westeurope"}}
+		]},
+		{"mode": "managed", "type": "azurerm_resource_group", "name": "example", "instances": [{"attributes": {"name": "rg"}}]}
+	]
+}`
+
+func SetupMockDeposedState(t *testing.T) *Deployment {
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromBytes([]byte(MockDeposedState))
+	return testStruct
+}
+
+func TestQueryLeavesOutDeposedObjects(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockDeposedState(t)
+
+	instances := testStruct.Query().Type("azurerm_storage_account").Instances()
+
+	assert.Equal(1, len(instances), "Deposed object was returned")
+	assert.Equal("current", instances[0].Attributes()["name"], "Current instance was not returned")
+}
+
+func TestQueryIncludeDeposed(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockDeposedState(t)
+
+	instances := testStruct.Query().Type("azurerm_storage_account").IncludeDeposed().Instances()
+
+	assert.Equal(2, len(instances), "Deposed object was not returned")
+	assert.Equal([]string{"azurerm_storage_account.this", "azurerm_storage_account.this"}, testStruct.Query().IncludeDeposed().Type("azurerm_storage_account").Addresses(), "Deposed object did not share the address of the current instance")
+}
+
+func TestQueryResourcesAreSortedByResourceAddress(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromBytes([]byte(`{"resources": [
+		{"mode": "managed", "type": "azurerm_subnet", "name": "this", "instances": [{"index_key": 0, "attributes": {}}]},
+		{"mode": "managed", "type": "azurerm_subnet", "name": "this-legacy", "instances": [{"attributes": {}}]}
+	]}`))
+
+	resources := testStruct.Query().Resources()
+
+	assert.Equal("this", resources[0].Name, "Resources were sorted by instance address rather than resource address")
+	assert.Equal("this-legacy", resources[1].Name, "Resources were sorted by instance address rather than resource address")
+}
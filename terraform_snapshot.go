@@ -0,0 +1,235 @@
+package terratestPlus
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	StateSnapshot is a copy of every instance in the State at a point in time, taken with Deployment.SnapshotState().
+
+Instances are keyed by their cleaned instance address (e.g. `module.platforms[alpha].azurerm_storage_account.this[0]`). Deposed objects have ` (deposed <key>)` appended.
+*/
+type StateSnapshot struct {
+	Serial    int
+	Instances map[string]map[string]interface{}
+}
+
+/*
+	StateDiff is the difference between a StateSnapshot and a later State, returned by Deployment.DiffState().
+
+Added and Removed are sorted instance addresses. Changed holds every instance in both with at least one changed attribute, sorted by address.
+*/
+type StateDiff struct {
+	Added   []string
+	Removed []string
+	Changed []InstanceDiff
+}
+
+// InstanceDiff is an instance whose attributes changed between a StateSnapshot and a later State.
+type InstanceDiff struct {
+	Address    string
+	Attributes []AttributeDiff
+}
+
+/*
+	AttributeDiff is a single changed attribute, with Path in the same dotted format as GetInstanceAttributeValuesOfResource (e.g. `tags.environment` or `network_rules.0.ip_rules`).
+
+Before is nil for an attribute that was added and After is nil for one that was removed.
+*/
+type AttributeDiff struct {
+	Path   string
+	Before interface{}
+	After  interface{}
+}
+
+/*
+	SnapshotState copies every instance in the current State so it can be compared with a later State by DiffState.
+
+e.g. to test that re-applying with a changed var file only touched the expected resources:
+
+	snap := d.SnapshotState()
+	// change the var file and DeployInfrastructure again
+	diff := d.DiffState(snap)
+*/
+func (d *Deployment) SnapshotState() *StateSnapshot {
+	snapshot := &StateSnapshot{Serial: d.State.Serial, Instances: make(map[string]map[string]interface{})}
+	for _, instance := range d.Query().IncludeDeposed().Instances() {
+		address := instance.Address.CleanString()
+		if instance.Instance.IsDeposed() {
+			address += " (deposed " + instance.Instance.Deposed + ")"
+		}
+		snapshot.Instances[address] = d.copyAttributes(instance.Attributes())
+	}
+	return snapshot
+}
+
+/*
+	DiffState compares a StateSnapshot with the current State, returning the instances that were added, removed or changed since the snapshot was taken.
+
+Attribute changes are found down to the nested attribute, e.g. a single changed tag is reported as `tags.environment` rather than `tags`. Lists that changed length are reported as a whole.
+*/
+func (d *Deployment) DiffState(snapshot *StateSnapshot) *StateDiff {
+	return diffSnapshots(snapshot, d.SnapshotState())
+}
+
+/*
+DiffSnapshots compares two snapshots, treating the first as the earlier one.
+*/
+func diffSnapshots(snapshot *StateSnapshot, current *StateSnapshot) *StateDiff {
+	diff := &StateDiff{Added: make([]string, 0), Removed: make([]string, 0), Changed: make([]InstanceDiff, 0)}
+
+	for _, address := range sortedKeys(current.Instances) {
+		before, existed := snapshot.Instances[address]
+		if !existed {
+			diff.Added = append(diff.Added, address)
+			continue
+		}
+
+		if attributes := diffAttributeValues("", before, current.Instances[address]); len(attributes) > 0 {
+			diff.Changed = append(diff.Changed, InstanceDiff{Address: address, Attributes: attributes})
+		}
+	}
+
+	for _, address := range sortedKeys(snapshot.Instances) {
+		if _, exists := current.Instances[address]; !exists {
+			diff.Removed = append(diff.Removed, address)
+		}
+	}
+	return diff
+}
+
+/*
+AssertStateChangedOnly fails the test if the State differs from the snapshot anywhere other than the given instance addresses, listing each unexpected change.
+
+Addresses use the same wildcards as FindByAddress, and a resource address matches all of its instances.
+*/
+func (d *Deployment) AssertStateChangedOnly(snapshot *StateSnapshot, addresses ...string) bool {
+	unexpected := make([]string, 0)
+	for _, change := range d.DiffState(snapshot).Descriptions() {
+		address := strings.SplitN(change, " (", 2)[0]
+		if !addressMatchesAny(address, addresses) {
+			unexpected = append(unexpected, change)
+		}
+	}
+
+	return assert.Emptyf(d.T, unexpected, "Unexpected changes to the State: %v", unexpected)
+}
+
+// IsEmpty is true when nothing changed.
+func (s *StateDiff) IsEmpty() bool {
+	return len(s.Added) == 0 && len(s.Removed) == 0 && len(s.Changed) == 0
+}
+
+// Addresses returns the sorted address of every added, removed or changed instance.
+func (s *StateDiff) Addresses() []string {
+	addresses := append(append([]string{}, s.Added...), s.Removed...)
+	for _, change := range s.Changed {
+		addresses = append(addresses, change.Address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+/*
+	Descriptions describes every change as "address (added)", "address (removed)" or "address (changed): paths".
+
+Attribute values are left out, as they may be sensitive.
+*/
+func (s *StateDiff) Descriptions() []string {
+	descriptions := make([]string, 0)
+	for _, address := range s.Added {
+		descriptions = append(descriptions, address+" (added)")
+	}
+	for _, address := range s.Removed {
+		descriptions = append(descriptions, address+" (removed)")
+	}
+	for _, change := range s.Changed {
+		descriptions = append(descriptions, change.String())
+	}
+	return descriptions
+}
+
+func (i InstanceDiff) String() string {
+	paths := make([]string, 0, len(i.Attributes))
+	for _, attribute := range i.Attributes {
+		paths = append(paths, attribute.Path)
+	}
+	return i.Address + " (changed): " + strings.Join(paths, ", ")
+}
+
+/*
+CopyAttributes deep copies the attributes through json, so later loads of the State cannot change a snapshot.
+*/
+func (d *Deployment) copyAttributes(attributes map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{})
+	raw, err := json.Marshal(attributes)
+	if err == nil {
+		err = json.Unmarshal(raw, &copied)
+	}
+	if err != nil {
+		assert.FailNow(d.T, "Could not copy instance attributes for a State snapshot", err.Error())
+	}
+	return copied
+}
+
+/*
+	DiffAttributeValues returns every path below prefix where before and after differ, sorted by path.
+
+Maps are compared key by key, and lists of the same length element by element. Anything else that differs is reported at its own path.
+*/
+func diffAttributeValues(prefix string, before interface{}, after interface{}) []AttributeDiff {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := make(map[string]bool)
+		for key := range beforeMap {
+			keys[key] = true
+		}
+		for key := range afterMap {
+			keys[key] = true
+		}
+
+		diffs := make([]AttributeDiff, 0)
+		for _, key := range sortedKeys(keys) {
+			diffs = append(diffs, diffAttributeValues(joinAttributePath(prefix, key), beforeMap[key], afterMap[key])...)
+		}
+		return diffs
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList && len(beforeList) == len(afterList) {
+		diffs := make([]AttributeDiff, 0)
+		for i := range beforeList {
+			diffs = append(diffs, diffAttributeValues(joinAttributePath(prefix, strconv.Itoa(i)), beforeList[i], afterList[i])...)
+		}
+		return diffs
+	}
+
+	if attributeValuesEqual(before, after) {
+		return nil
+	}
+	return []AttributeDiff{{Path: prefix, Before: before, After: after}}
+}
+
+func joinAttributePath(prefix string, segment string) string {
+	if prefix == "" {
+		return segment
+	}
+	return prefix + "." + segment
+}
+
+func addressMatchesAny(address string, patterns []string) bool {
+	for _, pattern := range patterns {
+		matcher := compileAddressPattern(pattern)
+		if matcher.MatchString(address) || matcher.MatchString(cleanAddressString(address)) {
+			return true
+		}
+	}
+	return false
+}
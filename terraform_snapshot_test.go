@@ -0,0 +1,100 @@
+package terratestPlus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var MockSnapshotStateBefore string = `{
+	"serial": 1,
+	"resources": [
+		{"mode": "managed", "type": "azurerm_resource_group", "name": "example", "instances": [{"attributes": {"name": "rg", "tags": {"environment": "dev", "owner": "platform"}}}]},
+		{"mode": "managed", "type": "azurerm_storage_account", "name": "this", "instances": [
+			{"index_key": "alpha", "attributes": {"name": "alpha", "network_rules": [{"ip_rules": ["10.0.0.1"]}]}},
+			{"index_key": "beta", "attributes": {"name": "beta", "network_rules": []}}
+		]}
+	]
+}`
+
+var MockSnapshotStateAfter string = `{
+	"serial": 2,
+	"resources": [
+		{"mode": "managed", "type": "azurerm_resource_group", "name": "example", "instances": [{"attributes": {"name": "rg", "tags": {"environment": "test", "owner": "platform"}}}]},
+		{"mode": "managed", "type": "azurerm_storage_account", "name": "this", "instances": [
+			{"index_key": "alpha", "attributes": {"name": "alpha", "network_rules": [{"ip_rules": ["10.0.0.2"]}]}},
+			{"index_key": "gamma", "attributes": {"name": "gamma", "network_rules": []}}
+		]}
+	]
+}`
+
+func SetupMockSnapshot(t *testing.T) (*Deployment, *StateSnapshot) {
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromBytes([]byte(MockSnapshotStateBefore))
+	snapshot := testStruct.SnapshotState()
+	testStruct.LoadStateFromBytes([]byte(MockSnapshotStateAfter))
+	return testStruct, snapshot
+}
+
+func TestDiffStateOfUnchangedStateIsEmpty(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	diff := testStruct.DiffState(testStruct.SnapshotState())
+
+	assert.True(diff.IsEmpty(), "Diff of an unchanged State was not empty: %v", diff.Descriptions())
+}
+
+func TestDiffStateFindsAddedAndRemovedInstances(t *testing.T) {
+	assert := assert.New(t)
+	testStruct, snapshot := SetupMockSnapshot(t)
+
+	diff := testStruct.DiffState(snapshot)
+
+	assert.Equal([]string{"azurerm_storage_account.this[gamma]"}, diff.Added, "Added instance was not found")
+	assert.Equal([]string{"azurerm_storage_account.this[beta]"}, diff.Removed, "Removed instance was not found")
+	assert.Equal(1, snapshot.Serial, "Snapshot did not record the serial it was taken at")
+}
+
+func TestDiffStateFindsNestedAttributeChanges(t *testing.T) {
+	assert := assert.New(t)
+	testStruct, snapshot := SetupMockSnapshot(t)
+
+	diff := testStruct.DiffState(snapshot)
+
+	assert.Equal(2, len(diff.Changed), "Changed instances were not found")
+	assert.Equal("azurerm_resource_group.example", diff.Changed[0].Address, "Changes were not sorted by address")
+	assert.Equal([]AttributeDiff{{Path: "tags.environment", Before: "dev", After: "test"}}, diff.Changed[0].Attributes, "Only the changed tag should be reported")
+	assert.Equal("network_rules.0.ip_rules.0", diff.Changed[1].Attributes[0].Path, "List elements were not compared one by one")
+}
+
+func TestSnapshotIsNotChangedByLaterLoads(t *testing.T) {
+	assert := assert.New(t)
+	testStruct, snapshot := SetupMockSnapshot(t)
+
+	testStruct.State.Resources[0].Instances[0].RawAttributes["name"] = "changed"
+
+	assert.Equal("rg", snapshot.Instances["azurerm_resource_group.example"]["name"], "Snapshot shares attributes with the State")
+}
+
+func TestStateDiffDescriptionsLeaveOutValues(t *testing.T) {
+	assert := assert.New(t)
+	testStruct, snapshot := SetupMockSnapshot(t)
+
+	diff := testStruct.DiffState(snapshot)
+
+	assert.Equal([]string{
+		"azurerm_storage_account.this[gamma] (added)",
+		"azurerm_storage_account.this[beta] (removed)",
+		"azurerm_resource_group.example (changed): tags.environment",
+		"azurerm_storage_account.this[alpha] (changed): network_rules.0.ip_rules.0",
+	}, diff.Descriptions(), "Changes were not described")
+}
+
+func TestAssertStateChangedOnlyPassesForExpectedChanges(t *testing.T) {
+	assert := assert.New(t)
+	testStruct, snapshot := SetupMockSnapshot(t)
+
+	assert.True(testStruct.AssertStateChangedOnly(snapshot, "azurerm_resource_group.example", "azurerm_storage_account.this"), "Expected changes failed the test")
+}
@@ -2,7 +2,10 @@ package terratestPlus
 
 import (
 	"regexp"
+	"strings"
 
+	"github.com/gruntwork-io/terratest/modules/logger"
+	version "github.com/hashicorp/go-version"
 	"github.com/mednax-it/terratest-plus/deployment"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,6 +25,8 @@ func (d *Deployment) FindAllResourceType(resourceType string) map[string]*deploy
 /*
 FindAllResourceTypeByMode finds based on a type like `asurerm_kubernetes_cluster` and a mode like `data`.
 The resulting return will be a map of StateResource structs, allowing use of direct attribute calls, such as StateResource.
+
+The map is keyed by the cleaned terraform address, e.g. `azurerm_storage_account.this`, `module.platforms[alpha].azurerm_storage_account.this` or `data.azurerm_client_config.current`.
 */
 func (d *Deployment) FindAllResourceTypeByMode(resourceType string, mode string) map[string]*deployment.StateResource {
 
@@ -57,6 +62,145 @@ func (d *Deployment) FindByName(resourceName string) map[string]*deployment.Stat
 	return output
 }
 
+/*
+	FindByAddress finds every resource whose address matches a pattern, where `*` is a wildcard for a module name or key, resource type, resource name or instance key.
+
+e.g. `module.platforms[*].azurerm_storage_account.*` finds every storage account in every instance of module.platforms.
+
+The pattern may use the terraform format (`["key"]`) or the cleaned format (`[key]`). A `*` never crosses a `.` outside of brackets, so `azurerm_storage_account.*` only matches root module resources.
+
+If the pattern includes an instance key (e.g. `azurerm_storage_account.this[*]` or `...this[0]`), the returned resources only contain the matching instances.
+
+The return has the same shape and keys as FindAllResourceType.
+*/
+func (d *Deployment) FindByAddress(pattern string) map[string]*deployment.StateResource {
+	matcher := compileAddressPattern(pattern)
+
+	output := make(map[string]*deployment.StateResource)
+	for _, resource := range d.State.Resources {
+		address := d.resourceAddress(resource)
+		if matcher.MatchString(address.CleanString()) {
+			output[address.CleanString()] = resource
+			continue
+		}
+
+		instances := make([]*deployment.StateResourceInstance, 0)
+		for _, instance := range resource.Instances {
+			address.Key = instance.IndexKey.Value()
+			if matcher.MatchString(address.CleanString()) {
+				instances = append(instances, instance)
+			}
+		}
+
+		if len(instances) > 0 {
+			matched := *resource
+			matched.Instances = instances
+			output[address.ResourceAddress().CleanString()] = &matched
+		}
+	}
+	return output
+}
+
+/*
+	CompileAddressPattern turns an address pattern into a regex that matches the cleaned format of addresses.
+
+Outside of brackets `*` matches up to the next `.` or `[`, inside brackets it matches up to the closing `]`.
+*/
+func compileAddressPattern(pattern string) *regexp.Regexp {
+	pattern = normaliseModuleAddress(pattern)
+
+	var expression strings.Builder
+	expression.WriteString("^")
+	inBrackets := false
+	for _, char := range pattern {
+		switch {
+		case char == '*' && inBrackets:
+			expression.WriteString(`[^\]]*`)
+		case char == '*':
+			expression.WriteString(`[^.\[\]]*`)
+		default:
+			if char == '[' {
+				inBrackets = true
+			} else if char == ']' {
+				inBrackets = false
+			}
+			expression.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	expression.WriteString("$")
+
+	return regexp.MustCompile(expression.String())
+}
+
+/*
+	FindInstance finds a single instance of a resource by its resource address (in either format) and instance key, returning nil if there is no such instance.
+
+The key is given in its string form (see deployment.InstanceKey.String), the same as AssertInstanceKeys: the for_each key, the count index such as "0", or "" for a resource with neither.
+Deposed objects are never returned.
+
+e.g. `d.FindInstance("module.platforms[alpha].azurerm_storage_account.this", "0")`
+*/
+func (d *Deployment) FindInstance(address string, key string) *deployment.StateResourceInstance {
+	for _, instance := range d.findResourceInstances(address) {
+		if !instance.IsDeposed() && instance.IndexKey.String() == key {
+			return instance
+		}
+	}
+	return nil
+}
+
+/*
+	AssertInstanceKeys fails the test unless the resource at the address has exactly the expected instance keys, listing any that are missing or unexpected.
+
+Keys are compared by their string form (see deployment.InstanceKey.String), so count indexes are given as "0", "1" and so on. Order does not matter.
+
+e.g. to check for_each produced an instance for every key of a tfvars map, use `d.AssertInstanceKeys(address, keys...)` where keys is a []string of the map keys.
+*/
+func (d *Deployment) AssertInstanceKeys(address string, expectedKeys ...string) bool {
+	instances := d.findResourceInstances(address)
+	if instances == nil {
+		return assert.Failf(d.T, "Resource not found", "No resource found in State at %s", address)
+	}
+
+	found := make(map[string]bool)
+	for _, instance := range instances {
+		if !instance.IsDeposed() {
+			found[instance.IndexKey.String()] = true
+		}
+	}
+
+	missing := make([]string, 0)
+	expected := make(map[string]bool)
+	for _, key := range expectedKeys {
+		expected[key] = true
+		if !found[key] {
+			missing = append(missing, key)
+		}
+	}
+
+	unexpected := make([]string, 0)
+	for _, key := range sortedKeys(found) {
+		if !expected[key] {
+			unexpected = append(unexpected, key)
+		}
+	}
+
+	return assert.Truef(d.T, len(missing) == 0 && len(unexpected) == 0, "Instance keys of %s do not match. Missing: %v Unexpected: %v", address, missing, unexpected)
+}
+
+/*
+FindResourceInstances returns the instances of the resource at the address, or nil if there is no such resource.
+*/
+func (d *Deployment) findResourceInstances(address string) []*deployment.StateResourceInstance {
+	address = cleanAddressString(address)
+	for _, resource := range d.State.Resources {
+		if d.cleanTerraformAddress(resource) == address {
+			return resource.Instances
+		}
+	}
+	return nil
+}
+
 /*
 GetInstanceNames loops through a map of StateResources and gets the name of Each INSTANCE in that map (not the name of the Terraform Resource!)
 
@@ -87,6 +231,10 @@ Will Cause the test to fail if it cannot find the attribute on the provided list
 
 This is most useful for finding specific attributes not covered in StateResource and subsequent structs.
 
+The attribute name can be a path into nested blocks, such as `network_rules.0.ip_rules` or `identity.0.principal_id`. A `*` segment fans out over every element, so `site_config.*.ftps_state` pulls the value from every block. Fanned out values are appended individually to the returned slice.
+
+Paths are split on `.`, so a map key that itself contains a `.` (such as a tag named `app.kubernetes.io/name`) cannot be addressed. Get the parent map instead and read the key from it.
+
 Note if you provide a name, if you have different resource types with the same name (such as the terraform convention `this`) this function will produce incorrect results.
 */
 func (d *Deployment) GetInstanceAttributeValuesOfResource(identifier string, attribute_name string) []interface{} {
@@ -96,7 +244,7 @@ func (d *Deployment) GetInstanceAttributeValuesOfResource(identifier string, att
 	for _, v := range resources {
 		resource := v.(map[string]interface{})
 		if resource["mode"] == "managed" && (resource["type"] == identifier || resource["name"] == identifier) {
-			terraform_address := d.cleanTerraformAddress(resource)
+			terraform_address := d.resourceAddress(resource)
 
 			instances := resource["instances"].([]interface{})
 			for _, inst_val := range instances {
@@ -104,15 +252,11 @@ func (d *Deployment) GetInstanceAttributeValuesOfResource(identifier string, att
 				attributes := instance["attributes"].(map[string]interface{})
 
 				// verify the value exists before trying to append it
-				if val, ok := attributes[attribute_name]; ok {
-					all_values = append(all_values, val)
+				if values, err := lookupAttributePath(attributes, attribute_name); err == nil {
+					all_values = append(all_values, values...)
 				} else {
-					instance_name := terraform_address
-					if name, ok := attributes["name"]; ok {
-						instance_name = "." + name.(string)
-					}
-
-					assert.Failf(d.T, "Unable to find %s on %s", attribute_name, instance_name)
+					terraform_address.Key = instance["index_key"]
+					assert.Failf(d.T, "Unable to find attribute", "Unable to find %s on %s: %v", attribute_name, terraform_address.CleanString(), err)
 				}
 
 			}
@@ -123,6 +267,74 @@ func (d *Deployment) GetInstanceAttributeValuesOfResource(identifier string, att
 	return all_values
 }
 
+/*
+AssertNoTaintedInstances fails the test if any instance in the State is tainted, listing every tainted instance.
+
+Useful right after DeployInfrastructure, as an apply that partially failed leaves tainted instances behind.
+*/
+func (d *Deployment) AssertNoTaintedInstances() bool {
+	tainted := d.findInstances(func(instance *deployment.StateResourceInstance) bool {
+		return instance.IsTainted()
+	})
+
+	return assert.Emptyf(d.T, tainted, "Tainted instances found in State: %v", tainted)
+}
+
+/*
+AssertNoDeposedInstances fails the test if any deposed objects remain in the State, listing each with its deposed key.
+
+Deposed objects are left when a create_before_destroy replacement fails to destroy the old object.
+*/
+func (d *Deployment) AssertNoDeposedInstances() bool {
+	deposed := d.findInstances(func(instance *deployment.StateResourceInstance) bool {
+		return instance.IsDeposed()
+	})
+
+	return assert.Emptyf(d.T, deposed, "Deposed instances found in State: %v", deposed)
+}
+
+/*
+FindInstances returns a description (address, index key and deposed key) of every instance that matches the filter.
+*/
+func (d *Deployment) findInstances(filter func(instance *deployment.StateResourceInstance) bool) []string {
+	found := make([]string, 0)
+	for _, resource := range d.State.Resources {
+		for _, instance := range resource.Instances {
+			if !filter(instance) {
+				continue
+			}
+
+			address := d.resourceAddress(resource)
+			address.Key = instance.IndexKey.Value()
+			description := address.CleanString()
+			if instance.IsDeposed() {
+				description += " (deposed " + instance.Deposed + ")"
+			}
+			found = append(found, description)
+		}
+	}
+	return found
+}
+
+/*
+AssertStateTerraformVersion checks the `terraform_version` recorded in the State against a version constraint, such as `>= 1.6` or `>= 1.6, < 2.0`.
+
+The constraint syntax is the same as the terraform `required_version` setting.
+*/
+func (d *Deployment) AssertStateTerraformVersion(constraint string) bool {
+	constraints, err := version.NewConstraint(constraint)
+	if err != nil {
+		return assert.Failf(d.T, "Invalid version constraint", "Could not parse version constraint %s: %v", constraint, err)
+	}
+
+	stateVersion, err := version.NewVersion(d.State.TerraformVersion)
+	if err != nil {
+		return assert.Failf(d.T, "Invalid terraform version", "Could not parse terraform_version [%s] of the State: %v", d.State.TerraformVersion, err)
+	}
+
+	return assert.Truef(d.T, constraints.Check(stateVersion), "State was written by terraform %s which does not satisfy %s", d.State.TerraformVersion, constraint)
+}
+
 /*
 CompileAllRegexGroups takes a regex string and returns a map of the parameters.
 This Function requires the use of the regex functionally to name sub groups.
@@ -151,22 +363,41 @@ func CompileAllRegexGroups(regEx, stringToMatch string) map[string]string {
 }
 
 /*
-CleanTerraformAddress takes a resource and returns a terraform full address of module.type.name format.
+CleanTerraformAddress takes a resource and returns its terraform full address in the cleaned module.type.name format (see deployment.Address.CleanString).
+
+This is the key used by all the Find functions. Root module resources have no module prefix and data resources are prefixed with `data.`
 
 It will cause a FailNow if it cannot create the address (in order for cleanup on tests to continue!)
 */
 func (d *Deployment) cleanTerraformAddress(resource interface{}) string {
-	if _, ok := resource.(*deployment.StateResource); ok {
-		value := resource.(*deployment.StateResource)
-		return value.Module.(string) + "." + value.Type.(string) + "." + value.Name.(string)
-	}
+	return d.resourceAddress(resource).CleanString()
+}
+
+/*
+ResourceAddress takes a resource (either a StateResource or a RawState resource map) and builds its deployment.Address.
 
-	if _, ok := resource.(map[string]interface{}); ok {
-		value := resource.(map[string]interface{})
-		return value["module"].(string) + "." + value["type"].(string) + "." + value["name"].(string)
+It will cause a FailNow if it cannot create the address (in order for cleanup on tests to continue!)
+*/
+func (d *Deployment) resourceAddress(resource interface{}) deployment.Address {
+	var module, mode, resourceType, name interface{}
+
+	if value, ok := resource.(*deployment.StateResource); ok {
+		module, mode, resourceType, name = value.Module, value.Mode, value.Type, value.Name
+	} else if value, ok := resource.(map[string]interface{}); ok {
+		module, mode, resourceType, name = value["module"], value["mode"], value["type"], value["name"]
+	} else {
+		assert.FailNow(d.T, "Could not create a clean Terraform Address! Something is wrong in the TerratestPlus functions")
 	}
 
-	assert.FailNow(d.T, "Could not create a clean Terraform Address! Something is wrong in the TerratestPlus functions")
+	moduleString, _ := module.(string)
+	modeString, _ := mode.(string)
+	typeString, _ := resourceType.(string)
+	nameString, _ := name.(string)
 
-	return ""
+	address, err := deployment.NewResourceAddress(moduleString, modeString, typeString, nameString)
+	if err != nil {
+		logger.Logf(d.T, "Error parsing module address: %v", err)
+		assert.FailNow(d.T, "Could not create a clean Terraform Address! Something is wrong in the TerratestPlus functions")
+	}
+	return address
 }
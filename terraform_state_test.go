@@ -8,7 +8,7 @@ import (
 )
 
 var TestTypeOne string = "resourceType"
-var TestModule string = "resourceModule"
+var TestModule string = "module.resourceModule" // a real module path, as the Find functions key by the parsed address
 var TestNameOne string = "testNameOne"
 var TestNameTwo string = "testNameButDifferent"
 
@@ -40,16 +40,16 @@ func SetupMockState() *deployment.TerraformState {
 
 	resourceInstance := deployment.StateResourceInstance{
 		Attributes: &attributes,
-		IndexKey:   index1,
+		IndexKey:   deployment.NewInstanceKey(index1),
 	}
 	resourceInstanceTwo := deployment.StateResourceInstance{
 		Attributes: &attributesTwo,
-		IndexKey:   nil,
+		IndexKey:   deployment.NewInstanceKey(nil),
 	}
 
 	resourceInstanceThree := deployment.StateResourceInstance{
 		Attributes: &attributes,
-		IndexKey:   indexNumber,
+		IndexKey:   deployment.NewInstanceKey(indexNumber),
 	}
 
 	resource := deployment.StateResource{
@@ -224,7 +224,8 @@ func TestIndexKeysForStringsAsIndexFromMaps(t *testing.T) {
 
 	for key, resource := range resources {
 		for _, instance := range resource.Instances {
-			assert.Equalf(index1, instance.IndexKey, "Index key (%s) in %s resource does not equal expected %s", instance.IndexKey, key, index1)
+			assert.Truef(instance.IndexKey.IsForEach(), "Index key (%s) in %s resource is not a for_each key", instance.IndexKey, key)
+			assert.Equalf(index1, instance.IndexKey.String(), "Index key (%s) in %s resource does not equal expected %s", instance.IndexKey, key, index1)
 		}
 	}
 
@@ -238,7 +239,9 @@ func TestIndexKeysForNumbersAsIndexFromList(t *testing.T) {
 
 	for key, resource := range resources {
 		for _, instance := range resource.Instances {
-			assert.Equalf(indexNumber, instance.IndexKey.(int), "Index key (%s) in %s resource does not equal expected %s", instance.IndexKey.(int), key, indexNumber)
+			index, isCount := instance.IndexKey.Int()
+			assert.Truef(isCount, "Index key (%s) in %s resource is not a count index", instance.IndexKey, key)
+			assert.Equalf(indexNumber, index, "Index key (%s) in %s resource does not equal expected %d", instance.IndexKey, key, indexNumber)
 		}
 	}
 
@@ -256,3 +259,238 @@ func TestGetInstanceAttributeValuesReturnsAttributes(t *testing.T) {
 }
 
 // Go does not have a default "Expect Fail" option, so I would love a test right here that Tests the FailNow of the function above but alas, it isnt possible with default
+
+func TestLoadedStateHasMetadata(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	assert.Equal(4, testStruct.State.Version, "State version was not parsed")
+	assert.Equal("1.7.5", testStruct.State.TerraformVersion, "State terraform_version was not parsed")
+	assert.Equal(12, testStruct.State.Serial, "State serial was not parsed")
+	assert.Equal("3f2a4c1e-7b7d-4f0e-9a51-0d3c2b6e8f11", testStruct.State.Lineage, "State lineage was not parsed")
+}
+
+func TestLoadedStateHasOutputs(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	output, found := testStruct.State.Outputs["resource_group_name"]
+	assert.True(found, "Output [resource_group_name] was not found in the State")
+	assert.Equal("TEMP-DeleteMe-Testing", output.Value, "Output value was not parsed")
+	assert.True(testStruct.State.Outputs["storage_account_key"].Sensitive, "Output sensitive flag was not parsed")
+}
+
+func TestLoadedStateHasCheckResults(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	assert.Equal(1, len(testStruct.State.CheckResults), "Check results were not parsed")
+	assert.Equal("check.storage_health", testStruct.State.CheckResults[0].ConfigAddr, "Check result address was not parsed")
+}
+
+func TestAssertStateTerraformVersionPassesForSatisfiedConstraint(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.State = &deployment.TerraformState{TerraformVersion: "1.7.5"}
+
+	passed := testStruct.AssertStateTerraformVersion(">= 1.6, < 2.0")
+
+	assert.True(passed, "Terraform version 1.7.5 should satisfy >= 1.6, < 2.0")
+}
+
+func TestLoadedStateHasInstanceMetadata(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	instance := testStruct.State.Resources[1].Instances[0]
+	assert.Equal(4, instance.SchemaVersion, "Instance schema_version was not parsed")
+	assert.Equal([]string{"azurerm_resource_group.example"}, instance.Dependencies, "Instance dependencies were not parsed")
+	assert.False(instance.IsTainted(), "Instance should not be tainted")
+	assert.False(instance.IsDeposed(), "Instance should not be deposed")
+}
+
+func TestTaintedAndDeposedInstancesAreFound(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.State = SetupMockState()
+	testStruct.State.Resources[0].Instances[0] = &deployment.StateResourceInstance{IndexKey: deployment.NewInstanceKey(index1), Status: "tainted"}
+	testStruct.State.Resources[2].Instances = append(testStruct.State.Resources[2].Instances, &deployment.StateResourceInstance{Deposed: "00000001"})
+	expectedTainted := TestModule + "." + TestTypeOne + "." + TestNameOne + "[" + index1 + "]"
+	expectedDeposed := TestModule + "." + TestTypeOne + "." + TestNameThree + " (deposed 00000001)"
+
+	tainted := testStruct.findInstances(func(instance *deployment.StateResourceInstance) bool { return instance.IsTainted() })
+	deposed := testStruct.findInstances(func(instance *deployment.StateResourceInstance) bool { return instance.IsDeposed() })
+
+	assert.Equal([]string{expectedTainted}, tainted, "Tainted instance was not found")
+	assert.Equal([]string{expectedDeposed}, deposed, "Deposed instance was not found")
+}
+
+func TestAssertNoTaintedInstancesPassesOnCleanState(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	assert.True(testStruct.AssertNoTaintedInstances(), "Clean State reported tainted instances")
+	assert.True(testStruct.AssertNoDeposedInstances(), "Clean State reported deposed instances")
+}
+
+func TestFindAllResourceTypeKeysRootAndModuleResources(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	foundResources := testStruct.FindAllResourceType("azurerm_storage_account")
+
+	assert.Contains(foundResources, "azurerm_storage_account.example", "Root module resource was not keyed without a module")
+	assert.Contains(foundResources, "module.platforms[alpha].azurerm_storage_account.this", "Module resource was not keyed with the cleaned module")
+	assert.Contains(foundResources, "module.platforms[beta].azurerm_storage_account.this", "Module resource was not keyed with the cleaned module")
+}
+
+func TestFindAllResourceTypeByModeKeysDataResources(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	foundResources := testStruct.FindAllResourceTypeByMode("azurerm_client_config", "data")
+
+	assert.Contains(foundResources, "data.azurerm_client_config.current", "Data resource was not keyed with the data prefix")
+}
+
+func TestFindByAddressWildcardModuleKeysAndNames(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	foundResources := testStruct.FindByAddress("module.platforms[*].azurerm_storage_account.*")
+
+	assert.Equal(2, len(foundResources), "Wildcard did not find the storage account in every module instance")
+	assert.Contains(foundResources, "module.platforms[alpha].azurerm_storage_account.this", "Key was not the same as FindAllResourceType")
+	assert.Contains(foundResources, "module.platforms[beta].azurerm_storage_account.this", "Key was not the same as FindAllResourceType")
+}
+
+func TestFindByAddressWildcardDoesNotCrossModules(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	foundResources := testStruct.FindByAddress("azurerm_storage_account.*")
+
+	assert.Equal(1, len(foundResources), "Root wildcard matched resources inside modules")
+	assert.Contains(foundResources, "azurerm_storage_account.example", "Root resource was not found")
+}
+
+func TestFindByAddressAcceptsTerraformFormat(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	foundResources := testStruct.FindByAddress(`module.platforms["alpha"].azurerm_storage_account.this`)
+
+	assert.Equal(1, len(foundResources), "Quoted module key did not match the cleaned address")
+}
+
+func TestFindByAddressInstanceKeysFilterInstances(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.State = SetupMockState()
+	key := TestModule + "." + TestTypeOne + "." + TestNameOne
+
+	foundResources := testStruct.FindByAddress(TestModule + "." + TestTypeOne + ".*[" + index1 + "]")
+
+	assert.Equal(2, len(foundResources), "Instance key pattern did not find the resources with that key")
+	assert.Equal(1, len(foundResources[key].Instances), "Instances without the key were not filtered out")
+	assert.Equal(2, len(testStruct.State.Resources[0].Instances), "Filtering instances changed the State")
+}
+
+func TestFindByAddressWildcardInstanceKeys(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.State = SetupMockState()
+	key := TestModule + "." + TestTypeOne + "." + TestNameOne
+
+	foundResources := testStruct.FindByAddress(TestModule + "." + TestTypeOne + "." + TestNameOne + "[*]")
+
+	assert.Equal(1, len(foundResources[key].Instances), "Wildcard instance key matched an instance without a key")
+}
+
+func TestLoadedStateHasCountIndexesAsInts(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	instance := testStruct.FindInstance(`module.platforms["alpha"].azurerm_storage_account.this`, "0")
+
+	assert.NotNil(instance, "Instance was not found by its count index")
+	assert.True(instance.IndexKey.IsCount(), "Index from json was not a count index")
+}
+
+func TestFindInstanceMatchesKeyKinds(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.State = SetupMockState()
+	address := TestModule + "." + TestTypeOne + "." + TestNameOne
+
+	assert.Equal(TestAttributeName, testStruct.FindInstance(address, index1).Attributes.Name, "for_each instance was not found")
+	assert.Equal(TestAttributeNameTwo, testStruct.FindInstance(address, "").Attributes.Name, "Instance without a key was not found")
+	assert.Nil(testStruct.FindInstance(address, "missing"), "Missing key returned an instance")
+	assert.Nil(testStruct.FindInstance("azurerm_x.missing", ""), "Missing resource returned an instance")
+}
+
+func TestAssertInstanceKeysPassesForExactKeys(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.State = SetupMockState()
+
+	assert.True(testStruct.AssertInstanceKeys(TestModule+"."+TestTypeOne+"."+TestNameTwo, index1), "Exact for_each keys did not pass")
+	assert.True(testStruct.AssertInstanceKeys(TestModule+"."+TestTypeOne+"."+TestNameThree, "0"), "Count index did not match its string form")
+}
+
+func TestAssertInstanceKeysAcceptsVarFileKeys(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.LoadStateFromBytes([]byte(`{"resources": [
+		{"mode": "managed", "type": "azurerm_storage_account", "name": "this", "instances": [
+			{"index_key": "alpha", "attributes": {}},
+			{"index_key": "beta", "attributes": {}}
+		]}
+	]}`))
+	testStruct.VarFileValues = map[string]interface{}{"accounts": map[string]interface{}{"alpha": "eastus", "beta": "westeurope"}}
+
+	keys := make([]string, 0)
+	for key := range testStruct.VarFileValues["accounts"].(map[string]interface{}) {
+		keys = append(keys, key)
+	}
+
+	assert.True(testStruct.AssertInstanceKeys("azurerm_storage_account.this", keys...), "Keys of a var file map did not match")
+	assert.NotNil(testStruct.FindInstance("azurerm_storage_account.this", keys[0]), "Instance was not found by the same key")
+}
+
+func TestLoadedStateHasSensitivePaths(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+
+	testStruct.LoadStateFromFile("testdata/sensitive.tfstate")
+
+	assert.Equal([]string{"primary_access_key", "primary_connection_string", "network_rules.0.ip_rules"}, testStruct.State.Resources[0].Instances[0].SensitivePaths(), "Sensitive attributes were not turned into attribute paths")
+}
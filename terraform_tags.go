@@ -0,0 +1,137 @@
+package terratestPlus
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	RequiredTagsOptions are the optional settings of AssertRequiredTags. A nil RequiredTagsOptions checks every taggable instance.
+
+ExcludeTypes are resource types to skip, such as `azurerm_resource_group`. ExcludeAddresses are address patterns to skip, using the same wildcards as FindByAddress (e.g. `module.legacy.*.*` or `azurerm_storage_account.*`).
+*/
+type RequiredTagsOptions struct {
+	ExcludeTypes     []string
+	ExcludeAddresses []string
+}
+
+/*
+	AssertRequiredTags checks the `tags` of every managed instance in the State that has a tags attribute, failing the test with every violation grouped by address.
+
+The required tags can be:
+  - a []string of tag keys that must be present, with any value
+  - a map[string]string of tag keys and the exact value each must have. An empty value only requires the key to be present
+  - a map[string]*regexp.Regexp of tag keys and a regex each value must match
+
+e.g. `d.AssertRequiredTags(map[string]string{"environment": "prod", "owner": ""}, &RequiredTagsOptions{ExcludeTypes: []string{"azurerm_resource_group"}})`
+*/
+func (d *Deployment) AssertRequiredTags(required interface{}, options *RequiredTagsOptions) bool {
+	violations := d.TagViolations(required, options)
+
+	descriptions := make([]string, 0, len(violations))
+	for _, address := range sortedKeys(violations) {
+		descriptions = append(descriptions, address+": "+strings.Join(violations[address], "; "))
+	}
+
+	return assert.Emptyf(d.T, descriptions, "Required tags are missing or invalid:\n%s", strings.Join(descriptions, "\n"))
+}
+
+/*
+	TagViolations returns every required tag violation of the instances in the State, keyed by cleaned instance address. See AssertRequiredTags for the supported required tags.
+
+Violations of each instance are sorted by tag key.
+*/
+func (d *Deployment) TagViolations(required interface{}, options *RequiredTagsOptions) map[string][]string {
+	rules, err := requiredTagRules(required)
+	if err != nil {
+		assert.Fail(d.T, "Invalid required tags", err.Error())
+		return nil
+	}
+	if options == nil {
+		options = new(RequiredTagsOptions)
+	}
+
+	violations := make(map[string][]string)
+	for _, instance := range d.Query().Mode("managed").Instances() {
+		address := instance.Address.CleanString()
+		attributes := instance.Attributes()
+		rawTags, taggable := attributes["tags"]
+		if !taggable || containsString(options.ExcludeTypes, instance.Address.Type) || addressMatchesAny(address, options.ExcludeAddresses) {
+			continue
+		}
+
+		tags, _ := rawTags.(map[string]interface{})
+		for _, rule := range rules {
+			if violation := rule.check(tags); violation != "" {
+				violations[address] = append(violations[address], violation)
+			}
+		}
+	}
+	return violations
+}
+
+type requiredTagRule struct {
+	key     string
+	value   string
+	pattern *regexp.Regexp
+}
+
+/*
+RequiredTagRules turns the required tags passed to AssertRequiredTags into rules sorted by tag key.
+*/
+func requiredTagRules(required interface{}) ([]requiredTagRule, error) {
+	rules := make([]requiredTagRule, 0)
+	switch typed := required.(type) {
+	case []string:
+		for _, key := range typed {
+			rules = append(rules, requiredTagRule{key: key})
+		}
+	case map[string]string:
+		for key, value := range typed {
+			rules = append(rules, requiredTagRule{key: key, value: value})
+		}
+	case map[string]*regexp.Regexp:
+		for key, pattern := range typed {
+			rules = append(rules, requiredTagRule{key: key, pattern: pattern})
+		}
+	default:
+		return nil, fmt.Errorf("required tags must be a []string, map[string]string or map[string]*regexp.Regexp, not %T", required)
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].key < rules[j].key
+	})
+	return rules, nil
+}
+
+/*
+Check describes how the tags violate the rule, or returns an empty string if they do not.
+*/
+func (r requiredTagRule) check(tags map[string]interface{}) string {
+	rawValue, present := tags[r.key]
+	if !present {
+		return fmt.Sprintf("missing tag %s", r.key)
+	}
+
+	value := fmt.Sprint(rawValue)
+	if r.pattern != nil && !r.pattern.MatchString(value) {
+		return fmt.Sprintf("tag %s is %q which does not match %s", r.key, value, r.pattern)
+	}
+	if r.value != "" && value != r.value {
+		return fmt.Sprintf("tag %s is %q, expected %q", r.key, value, r.value)
+	}
+	return ""
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
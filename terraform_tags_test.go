@@ -0,0 +1,56 @@
+package terratestPlus
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagViolationsOfRequiredKeys(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	violations := testStruct.TagViolations([]string{"environment", "owner"}, nil)
+
+	assert.Equal(map[string][]string{
+		"azurerm_resource_group.example":                          {"missing tag environment", "missing tag owner"},
+		"azurerm_storage_account.example":                         {"missing tag owner"},
+		"module.platforms[alpha].azurerm_storage_account.this[0]": {"missing tag owner"},
+	}, violations, "Missing tags were not grouped by address")
+}
+
+func TestTagViolationsOfExactValues(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	violations := testStruct.TagViolations(map[string]string{"environment": "Testing", "owner": "data"}, &RequiredTagsOptions{ExcludeTypes: []string{"azurerm_resource_group"}})
+
+	assert.Equal([]string{`tag owner is "platform", expected "data"`}, violations["module.platforms[beta].azurerm_storage_account.this[0]"], "Wrong tag value was not reported")
+	assert.Equal([]string{"missing tag owner"}, violations["azurerm_storage_account.example"], "Key with the expected value was reported")
+	assert.NotContains(violations, "azurerm_resource_group.example", "Excluded type was checked")
+}
+
+func TestTagViolationsOfRegexValues(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	violations := testStruct.TagViolations(map[string]*regexp.Regexp{"environment": regexp.MustCompile(`^(Testing|Production)$`), "info": regexp.MustCompile(`^Temp-`)}, &RequiredTagsOptions{ExcludeAddresses: []string{"module.platforms[*].*.*", "azurerm_resource_group.*"}})
+
+	assert.Empty(violations, "Matching tags or excluded addresses were reported: %v", violations)
+}
+
+func TestAssertRequiredTagsPassesWhenTagsArePresent(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := SetupMockLoadedState(t)
+
+	assert.True(testStruct.AssertRequiredTags([]string{"environment"}, &RequiredTagsOptions{ExcludeTypes: []string{"azurerm_resource_group"}}), "Present tags failed the test")
+}
+
+func TestRequiredTagRulesRejectsUnsupportedTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := requiredTagRules(map[string]int{"environment": 1})
+
+	assert.Error(err, "Unsupported required tags were accepted")
+}
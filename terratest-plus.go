@@ -6,6 +6,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -24,6 +25,8 @@ type SetupTerraformOptions struct {
 	BackendDirectoryPath   string `default:"backends/"`
 	Workspace              string
 	Parallelism            int
+	KeepRawModuleAddresses bool
+	CheckIdempotency       bool
 }
 
 func defaultValues(o *SetupTerraformOptions) {
@@ -57,7 +60,8 @@ func getDefaultTag(o SetupTerraformOptions, property string) string {
 
 type Deployment struct {
 	deployment.D
-	performCleanup bool
+	performCleanup   bool
+	checkIdempotency bool
 }
 
 /*
@@ -74,6 +78,8 @@ func (d *Deployment) SetupTerraform(t *testing.T, options *SetupTerraformOptions
 		options = new(SetupTerraformOptions)
 	}
 	defaultValues(options)
+	d.KeepRawModuleAddresses = options.KeepRawModuleAddresses
+	d.checkIdempotency = options.CheckIdempotency
 
 	d.getTFSource(options)
 	d.getTFVars(options)
@@ -104,24 +110,53 @@ func (d *Deployment) SetupTerraform(t *testing.T, options *SetupTerraformOptions
 Init is controlled by both the RunInit flag and the Test Structure Stage variable SKIP_terraformin_init
 
 Apply is controlled by the Test Structure Stage variable SKIP_terraform_apply
+
+If CheckIdempotency was set in SetupTerraformOptions, AssertIdempotent is run after apply. It is controlled by the Test Structure Stage variable SKIP_terraform_idempotency
+
+After apply, AssertChecksPass fails the test if any terraform `check` block or condition failed. It is controlled by the Test Structure Stage variable SKIP_terraform_checks
 */
 func (d *Deployment) DeployInfrastructure() {
 
-	test_structure.RunTestStage(d.T, "terraform_init", func() {
-		if d.RunInit {
-			terraform.Init(d.T, &d.TerraformOptions)
-		}
-	})
-
-	terraform.WorkspaceSelectOrNew(d.T, &d.TerraformOptions, d.WorkspaceName)
+	d.initWorkspace()
 
 	test_structure.RunTestStage(d.T, "terraform_apply", func() {
 		terraform.Apply(d.T, &d.TerraformOptions)
 	})
 
-	d.State = new(deployment.TerraformState)
 	d.GetState()
 	d.getOutputValues()
+
+	d.checkDeployment()
+}
+
+/*
+CheckDeployment runs the checks DeployInfrastructure does on the State after apply, each in its own Test Structure Stage.
+*/
+func (d *Deployment) checkDeployment() {
+	test_structure.RunTestStage(d.T, "terraform_checks", func() {
+		d.AssertChecksPass()
+	})
+
+	if d.checkIdempotency {
+		test_structure.RunTestStage(d.T, "terraform_idempotency", func() {
+			d.AssertIdempotent()
+		})
+	}
+}
+
+/*
+	InitWorkspace performs Terraform Init (controlled by the RunInit flag and SKIP_terraform_init) and selects the Workspace.
+
+Shared by DeployInfrastructure and PlanInfrastructure.
+*/
+func (d *Deployment) initWorkspace() {
+	test_structure.RunTestStage(d.T, "terraform_init", func() {
+		if d.RunInit {
+			terraform.Init(d.T, &d.TerraformOptions)
+		}
+	})
+
+	terraform.WorkspaceSelectOrNew(d.T, &d.TerraformOptions, d.WorkspaceName)
 }
 
 /*
@@ -136,27 +171,116 @@ GetState sets the State struct var for use in various tests, as well as the RawS
 GetState should not need to be used in General Testing but is provided exposed just in case.
 
 Note: in order to make matching to a Struct easier, the terraform module names (which are usually like `["name"]` ) have been cleaned to just `[name]`.
+Only the `module` field is cleaned - set KeepRawModuleAddresses (or the SetupTerraformOptions flag of the same name) to keep the address exactly as terraform wrote it.
 */
 func (d *Deployment) GetState() {
 	tf_get_state, err := terraform.RunTerraformCommandAndGetStdoutE(d.T, &d.TerraformOptions, "state", "pull")
 
-	tf_get_state = strings.ReplaceAll(tf_get_state, "\n", "")
-	tf_get_state = strings.ReplaceAll(tf_get_state, "\\", "")
-	tf_get_state = strings.ReplaceAll(tf_get_state, "[\"", "[")
-	tf_get_state = strings.ReplaceAll(tf_get_state, "\"]", "]")
-
 	if err != nil {
 		logger.Logf(d.T, "Error pulling State file: %v", err)
 		assert.FailNow(d.T, "State File was not able to be pulled - tests cannot run.")
 	}
-	json.Unmarshal([]byte(tf_get_state), &d.RawState)
-	_, err = marshmallow.Unmarshal([]byte(tf_get_state), d.State)
+
+	d.LoadStateFromBytes([]byte(tf_get_state))
+}
+
+/*
+LoadStateFromFile reads a state file (such as a captured `terraform.tfstate` fixture) from disk and loads it the same way GetState does.
+
+This allows the State helpers to be used without a deployment, such as unit testing assertion logic in CI without cloud credentials.
+*/
+func (d *Deployment) LoadStateFromFile(path string) {
+	raw_state, err := os.ReadFile(path)
+
+	if err != nil {
+		logger.Logf(d.T, "Error reading State file %s: %v", path, err)
+		assert.FailNow(d.T, "State File was not able to be read - tests cannot run.")
+	}
+
+	d.LoadStateFromBytes(raw_state)
+}
+
+/*
+LoadStateFromBytes takes the json of a state file and sets the State and RawState vars from it.
+
+It is the shared pipeline behind GetState and LoadStateFromFile.
+*/
+func (d *Deployment) LoadStateFromBytes(raw_state []byte) {
+	d.State = new(deployment.TerraformState)
+	d.RawState = nil
+
+	err := json.Unmarshal(raw_state, &d.RawState)
+	if err == nil {
+		_, err = marshmallow.Unmarshal(raw_state, d.State)
+	}
 
 	if err != nil {
 		logger.Logf(d.T, "Error building State Map: %v", err)
 		assert.FailNow(d.T, "Could not build map of State File - Tests cannot run.")
 	}
 
+	d.linkRawAttributes()
+	d.setOutputs(d.State.Outputs)
+
+	if !d.KeepRawModuleAddresses {
+		d.normaliseModuleAddresses()
+	}
+}
+
+/*
+	LinkRawAttributes sets RawAttributes on every instance of the State from the matching instance in RawState.
+
+Both are decoded from the same json, so resources and instances are in the same order.
+*/
+func (d *Deployment) linkRawAttributes() {
+	resources, _ := d.RawState["resources"].([]interface{})
+	for i, resource := range d.State.Resources {
+		if i >= len(resources) {
+			return
+		}
+		rawResource, _ := resources[i].(map[string]interface{})
+		rawInstances, _ := rawResource["instances"].([]interface{})
+
+		for j, instance := range resource.Instances {
+			if j >= len(rawInstances) {
+				break
+			}
+			rawInstance, _ := rawInstances[j].(map[string]interface{})
+			instance.RawAttributes, _ = rawInstance["attributes"].(map[string]interface{})
+		}
+	}
+}
+
+var moduleKeyRegex = regexp.MustCompile(`\["((?:[^"\\]|\\.)*)"\]`)
+
+/*
+	NormaliseModuleAddress turns the module keys of an address into the cleaned form, e.g. `module.x["a"]` becomes `module.x[a]`.
+
+Count indexes (`module.x[0]`) are left untouched.
+*/
+func normaliseModuleAddress(address string) string {
+	return moduleKeyRegex.ReplaceAllString(address, "[$1]")
+}
+
+/*
+	NormaliseModuleAddresses cleans the `module` field of every resource in both State and RawState.
+
+Only the module field is touched, attribute values are left exactly as terraform wrote them.
+*/
+func (d *Deployment) normaliseModuleAddresses() {
+	for _, resource := range d.State.Resources {
+		if module, ok := resource.Module.(string); ok {
+			resource.Module = normaliseModuleAddress(module)
+		}
+	}
+
+	resources, _ := d.RawState["resources"].([]interface{})
+	for _, v := range resources {
+		resource, _ := v.(map[string]interface{})
+		if module, ok := resource["module"].(string); ok {
+			resource["module"] = normaliseModuleAddress(module)
+		}
+	}
 }
 
 /*
@@ -327,14 +451,47 @@ func (d *Deployment) getTFWorkspace(options *SetupTerraformOptions) {
 }
 
 /*
-	GetOutputValues stores the outputs of the Terraform in a map.
+	GetOutputValues stores the outputs of the Terraform in the Outputs (typed, with the sensitive flag) and OutputValues (values only) maps.
+
+Sensitive outputs are stored in OutputValues as deployment.SensitiveValue. Use GetOutput to read their real value.
 
 Must be called after terraform apply has been run.
 
-As such it is called automatically as part of the DeployInfrastructure
+As such it is called automatically as part of the DeployInfrastructure.
+
+The `terraform output -json` command is never logged, as it prints sensitive values.
 */
 func (d *Deployment) getOutputValues() {
-	d.OutputValues = terraform.OutputAll(d.T, &d.TerraformOptions)
+	options := d.TerraformOptions
+	options.Logger = logger.Discard
+
+	tf_outputs, err := terraform.OutputJsonE(d.T, &options, "")
+	if err != nil {
+		logger.Logf(d.T, "Error getting Outputs: %v", err)
+		assert.FailNow(d.T, "Outputs were not able to be retrieved - tests cannot run.")
+	}
+
+	outputs := make(map[string]*deployment.StateOutput)
+	if err := json.Unmarshal([]byte(tf_outputs), &outputs); err != nil {
+		logger.Logf(d.T, "Error building Outputs Map: %v", err)
+		assert.FailNow(d.T, "Could not build map of Outputs - Tests cannot run.")
+	}
+	d.setOutputs(outputs)
+}
+
+/*
+SetOutputs sets both the Outputs and OutputValues maps from the typed outputs, masking sensitive values in OutputValues.
+*/
+func (d *Deployment) setOutputs(outputs map[string]*deployment.StateOutput) {
+	d.Outputs = outputs
+	d.OutputValues = make(map[string]interface{})
+	for name, output := range outputs {
+		if output.Sensitive {
+			d.OutputValues[name] = deployment.SensitiveValue
+			continue
+		}
+		d.OutputValues[name] = output.Value
+	}
 }
 
 func (d *Deployment) getAzureArmValues() map[string]string {
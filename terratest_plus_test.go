@@ -138,3 +138,113 @@ func TestBackendWhenSetByEnvVariable(t *testing.T) {
 	assert.Equalf(expectedPath, testStruct.BackendFilePath, "BackendPath of %s did not follow env value of  %s ", testStruct.VarFilePath, expectedPath)
 
 }
+
+func TestLoadStateFromFileBuildsState(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	expectedLength := 5
+
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	assert.Equalf(expectedLength, len(testStruct.State.Resources), "Incorrect number [%d] of resources loaded (should be %d)", len(testStruct.State.Resources), expectedLength)
+}
+
+func TestLoadStateFromFileBuildsRawState(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	expectedLength := 5
+
+	testStruct.LoadStateFromFile("testdata/terraform.tfstate")
+
+	resources := testStruct.RawState["resources"].([]interface{})
+	assert.Equalf(expectedLength, len(resources), "Incorrect number [%d] of raw resources loaded (should be %d)", len(resources), expectedLength)
+}
+
+func TestLoadStateFromBytesWorksWithStateHelpers(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	rawState, _ := os.ReadFile("testdata/terraform.tfstate")
+	expectedLength := 2
+
+	testStruct.LoadStateFromBytes(rawState)
+
+	foundResources := testStruct.FindByName("this")
+	assert.Equalf(expectedLength, len(foundResources), "Incorrect number [%d] of resources returned (should be %d)", len(foundResources), expectedLength)
+}
+
+func TestLoadStateFromBytesReplacesPreviousState(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.State = SetupMockState()
+	testStruct.RawState = SetupMockRawState()
+
+	testStruct.LoadStateFromBytes([]byte(`{"resources": []}`))
+
+	assert.Empty(testStruct.State.Resources, "Previous State resources were not replaced")
+	assert.Empty(testStruct.RawState["resources"], "Previous RawState resources were not replaced")
+}
+
+var MockEscapedState string = `{
+	"resources": [
+		{
+			"module": "module.platforms[\"alpha\"].module.storage[0]",
+			"mode": "managed",
+			"type": "azurerm_key_vault_certificate",
+			"name": "this",
+			"instances": [
+				{
+					"attributes": {
+						"certificate_data": "-----BEGIN CERTIFICATE-----\nMIIB\\path\n-----END CERTIFICATE-----\n",
+						"policy": "[\"Allow\"]"
+					}
+				}
+			]
+		}
+	]
+}`
+
+func TestLoadStateFromBytesKeepsAttributeValuesIntact(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	expectedCertificate := "-----BEGIN CERTIFICATE-----\nMIIB\\path\n-----END CERTIFICATE-----\n"
+	expectedPolicy := `["Allow"]`
+
+	testStruct.LoadStateFromBytes([]byte(MockEscapedState))
+
+	certificates := testStruct.GetInstanceAttributeValuesOfResource("azurerm_key_vault_certificate", "certificate_data")
+	policies := testStruct.GetInstanceAttributeValuesOfResource("azurerm_key_vault_certificate", "policy")
+	assert.Equal(expectedCertificate, certificates[0], "Certificate attribute was altered while loading state")
+	assert.Equal(expectedPolicy, policies[0], "Policy attribute was altered while loading state")
+}
+
+func TestLoadStateFromBytesNormalisesModuleAddresses(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	expectedModule := "module.platforms[alpha].module.storage[0]"
+
+	testStruct.LoadStateFromBytes([]byte(MockEscapedState))
+
+	rawResource := testStruct.RawState["resources"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(expectedModule, testStruct.State.Resources[0].Module, "State module address was not normalised")
+	assert.Equal(expectedModule, rawResource["module"], "RawState module address was not normalised")
+}
+
+func TestKeepRawModuleAddressesLeavesModuleUntouched(t *testing.T) {
+	assert := assert.New(t)
+	testStruct := new(Deployment)
+	testStruct.T = t
+	testStruct.KeepRawModuleAddresses = true
+	expectedModule := `module.platforms["alpha"].module.storage[0]`
+
+	testStruct.LoadStateFromBytes([]byte(MockEscapedState))
+
+	rawResource := testStruct.RawState["resources"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(expectedModule, testStruct.State.Resources[0].Module, "State module address was changed")
+	assert.Equal(expectedModule, rawResource["module"], "RawState module address was changed")
+}